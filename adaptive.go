@@ -0,0 +1,89 @@
+package polecalc
+
+import "math"
+
+// Maximum number of times a cell of the GridLength mesh may be subdivided
+// when env.AdaptiveMaxDepth is not set.
+const DefaultAdaptiveMaxDepth = 6
+
+// Gauss-Legendre nodes and weights on [0, 1]
+var (
+	gauss2Nodes   = [2]float64{0.5 - 0.5/math.Sqrt(3), 0.5 + 0.5/math.Sqrt(3)}
+	gauss2Weights = [2]float64{0.5, 0.5}
+	gauss3Nodes   = [3]float64{0.5 - 0.5*math.Sqrt(0.6), 0.5, 0.5 + 0.5*math.Sqrt(0.6)}
+	gauss3Weights = [3]float64{5.0 / 18.0, 4.0 / 9.0, 5.0 / 18.0}
+)
+
+// Average of worker over the Brillouin zone by adaptive quadrature.
+// The zone is divided into the pointsPerSide^2 cells of the square mesh.  On
+// each cell the average is taken with 2x2 and 3x3 point Gauss-Legendre
+// rules; if the two differ by more than tolerance, the cell is split into
+// quarters which are treated the same way, up to maxDepth times.  Since each
+// cell carries 1/N of the total, the error in the result is then about
+// tolerance or less.  Also returns the number of cells which still missed
+// tolerance at maxDepth; if it is nonzero the error may be larger.
+func AdaptiveAverage(pointsPerSide uint32, worker Consumer, tolerance float64, maxDepth uint) (float64, uint64) {
+	L := uint64(pointsPerSide)
+	N := L * L
+	step := 2 * math.Pi / float64(pointsPerSide)
+	sum, compensate := 0.0, 0.0
+	unconverged := uint64(0)
+	for i := uint64(0); i < N; i++ {
+		cell, missed := adaptiveCell(worker, SquareAt(i, pointsPerSide), step, tolerance, maxDepth)
+		sum, compensate = KahanSum(cell, sum, compensate)
+		unconverged += missed
+	}
+	return sum / float64(N), unconverged
+}
+
+// Average of worker over the square cell with lower-left corner origin and
+// side length step, and the number of subcells left unconverged at depth 0.
+func adaptiveCell(worker Consumer, origin Vector2, step, tolerance float64, depth uint) (float64, uint64) {
+	coarse, fine := 0.0, 0.0
+	for i, x := range gauss2Nodes {
+		for j, y := range gauss2Nodes {
+			k := Vector2{origin.X + x*step, origin.Y + y*step}
+			coarse += gauss2Weights[i] * gauss2Weights[j] * worker(k)
+		}
+	}
+	for i, x := range gauss3Nodes {
+		for j, y := range gauss3Nodes {
+			k := Vector2{origin.X + x*step, origin.Y + y*step}
+			fine += gauss3Weights[i] * gauss3Weights[j] * worker(k)
+		}
+	}
+	if math.Abs(fine-coarse) <= tolerance {
+		return fine, 0
+	} else if depth == 0 {
+		return fine, 1
+	}
+	half := 0.5 * step
+	result, unconverged := 0.0, uint64(0)
+	for _, offset := range []Vector2{Vector2{0, 0}, Vector2{half, 0}, Vector2{0, half}, Vector2{half, half}} {
+		sub, missed := adaptiveCell(worker, origin.Add(offset), half, tolerance, depth-1)
+		result += 0.25 * sub
+		unconverged += missed
+	}
+	return result, unconverged
+}
+
+// Average of worker over the Brillouin zone on the GridLength mesh, using
+// AdaptiveAverage if env.AdaptiveTolerance is set, or else SymmetricAverage
+// if env.Symmetrize is set.  EnvironmentFromObject rejects setting both.
+// Cells left unconverged by AdaptiveAverage are not reported here; call it
+// directly to check them.  Only averages are refined: Minimum and Maximum
+// (and so EpsilonMin) always use the uniform mesh.
+func AverageK(env Environment, worker Consumer) float64 {
+	if env.AdaptiveTolerance <= 0.0 {
+		if env.Symmetrize {
+			return SymmetricAverage(env, worker)
+		}
+		return Average(env.GridLength, worker)
+	}
+	maxDepth := env.AdaptiveMaxDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultAdaptiveMaxDepth
+	}
+	average, _ := AdaptiveAverage(env.GridLength, worker, env.AdaptiveTolerance, maxDepth)
+	return average
+}
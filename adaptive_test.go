@@ -0,0 +1,45 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// 1/((a - cos kx)(a - cos ky)) has average 1/(a^2 - 1) and, for a close to 1,
+// a sharp peak at k = 0 like the mu and F0 sums near the holon band minimum.
+// Adaptive refinement on a 16x16 mesh should beat a uniform 128x128 mesh
+// there.
+func TestAdaptiveAverageAccuracy(t *testing.T) {
+	a := 1.001
+	worker := func(k Vector2) float64 {
+		return 1 / ((a - math.Cos(k.X)) * (a - math.Cos(k.Y)))
+	}
+	expected := 1 / (a*a - 1)
+	env := Environment{GridLength: 16, AdaptiveTolerance: 1e-6}
+	adaptiveDiff := math.Abs(AverageK(env, worker)-expected) / expected
+	uniformDiff := math.Abs(Average(128, worker)-expected) / expected
+	if adaptiveDiff > 1e-8 || adaptiveDiff >= uniformDiff {
+		t.Fatalf("adaptive mesh not accurate enough (uniform %e, adaptive %e relative error)", uniformDiff, adaptiveDiff)
+	}
+}
+
+// A step in the integrand can't be resolved by a finite number of
+// refinements, which AdaptiveAverage should report; a smooth integrand
+// converges everywhere.
+func TestAdaptiveAverageUnconverged(t *testing.T) {
+	step := func(k Vector2) float64 {
+		if k.X+k.Y > 0.1 {
+			return 1.0
+		}
+		return 0.0
+	}
+	if _, unconverged := AdaptiveAverage(8, step, 1e-6, 2); unconverged == 0 {
+		t.Fatal("no unconverged cells reported for discontinuous integrand")
+	}
+	smooth := func(k Vector2) float64 {
+		return math.Cos(k.X) * math.Cos(k.Y)
+	}
+	if _, unconverged := AdaptiveAverage(8, smooth, 1e-6, 2); unconverged != 0 {
+		t.Fatalf("%d unconverged cells reported for smooth integrand", unconverged)
+	}
+}
@@ -2,7 +2,8 @@ package polecalc
 
 import "errors"
 
-var ErrorNoBracket string = "cannot find bracket"
+// Error returned when no bracket is found (test with errors.Is).
+var ErrorNoBracket = errors.New("cannot find bracket")
 
 // (should probably set these constants through a configuration method)
 // Number of steps to take in the first attempt to find a bracket.
@@ -61,7 +62,7 @@ func bracketHelper(f Func1D, left, right float64, bracketNum uint, maxBrackets i
 	// overshot bounds if without finding bracket if we get here
 	if bracketNum >= MaxBracketNumber {
 		// too many divisions
-		return nil, errors.New(ErrorNoBracket)
+		return nil, ErrorNoBracket
 	}
 	// not enough brackets - try again with smaller divisions
 	if len(brackets) == 0 {
@@ -0,0 +1,79 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+)
+
+// Values of Environment.Broadening.  An empty name means no broadening: each
+// delta function goes entirely into one bin.
+const (
+	LorentzianBroadening = "lorentzian"
+	GaussianBroadening   = "gaussian"
+)
+
+// Lorentzian tails are cut off at this many widths from the center.
+const LorentzianCutoff = 100.0
+
+// Gaussian tails are cut off at this many widths from the center.
+const GaussianCutoff = 6.0
+
+// Line shape used by DeltaBinner to spread each delta function over
+// neighbouring bins.
+type BroadeningKernel interface {
+	// integral of the (unit weight) kernel from -infinity to x
+	CDF(x float64) float64
+	// distance from the center beyond which the kernel is treated as 0
+	Cutoff() float64
+}
+
+// Lorentzian with half-width at half-maximum Eta.
+type LorentzianKernel struct {
+	Eta float64
+}
+
+func (kernel LorentzianKernel) CDF(x float64) float64 {
+	return 0.5 + math.Atan(x/kernel.Eta)/math.Pi
+}
+
+func (kernel LorentzianKernel) Cutoff() float64 {
+	return LorentzianCutoff * kernel.Eta
+}
+
+// Gaussian with standard deviation Sigma.
+type GaussianKernel struct {
+	Sigma float64
+}
+
+func (kernel GaussianKernel) CDF(x float64) float64 {
+	return 0.5 * (1 + math.Erf(x/(kernel.Sigma*math.Sqrt2)))
+}
+
+func (kernel GaussianKernel) Cutoff() float64 {
+	return GaussianCutoff * kernel.Sigma
+}
+
+// Kernel selected by env.Broadening with width env.BroadeningWidth, or nil
+// if there is no broadening.
+func (env *Environment) BroadeningKernel() BroadeningKernel {
+	kernel, err := kernelByName(env.Broadening, env.BroadeningWidth)
+	if err != nil {
+		panic(err)
+	}
+	return kernel
+}
+
+func kernelByName(name string, width float64) (BroadeningKernel, error) {
+	if name != "" && width <= 0.0 {
+		return nil, errors.New("broadening width must be positive")
+	}
+	switch name {
+	case "":
+		return nil, nil
+	case LorentzianBroadening:
+		return LorentzianKernel{width}, nil
+	case GaussianBroadening:
+		return GaussianKernel{width}, nil
+	}
+	return nil, errors.New("unknown broadening " + name)
+}
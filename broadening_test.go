@@ -0,0 +1,66 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// A single delta function broadened by a Gaussian should give bins following
+// the Gaussian line shape, with the total weight unchanged.
+func TestGaussianBroadening(t *testing.T) {
+	sigma := 0.3
+	deltaTerms := func(q Vector2) ([]float64, []float64) {
+		return []float64{0.05}, []float64{2.0}
+	}
+	binner := NewBroadenedDeltaBinner(deltaTerms, -5.0, 5.0, 1000, GaussianKernel{sigma})
+	bins := DeltaBin(4, binner)
+	step := binner.Step()
+	total := 0.0
+	for i, value := range bins {
+		total += value
+		center := binner.IndexToBinVar(i) + 0.5*step - 0.05
+		expected := 2.0 * step * math.Exp(-center*center/(2*sigma*sigma)) / (sigma * math.Sqrt(2*math.Pi))
+		if math.Abs(value-expected) > 1e-4 {
+			t.Fatalf("broadened bin %d incorrect (got %e, expected %e)", i, value, expected)
+		}
+	}
+	if math.Abs(total-2.0) > 1e-12 {
+		t.Fatalf("Gaussian broadening changed the total weight (%f)", total)
+	}
+}
+
+// Lorentzian tails are cut off at the bin range; the weight that would be
+// lost there is kept by renormalization.
+func TestLorentzianBroadeningWeight(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength, env.ImGc0Bins = 16, 128
+	k := Vector2{0.3, 1.1}
+	_, sharp := ZeroTempImGc0(*env, k)
+	env.Broadening, env.BroadeningWidth = LorentzianBroadening, 0.2
+	_, broad := ZeroTempImGc0(*env, k)
+	sharpSum, broadSum, sharpMax, broadMax := 0.0, 0.0, 0.0, 0.0
+	for i := range sharp {
+		sharpSum += sharp[i]
+		broadSum += broad[i]
+		sharpMax = math.Max(sharpMax, math.Abs(sharp[i]))
+		broadMax = math.Max(broadMax, math.Abs(broad[i]))
+	}
+	if math.Abs(sharpSum-broadSum) > 1e-12 {
+		t.Fatalf("Lorentzian broadening changed the total weight (%e to %e)", sharpSum, broadSum)
+	}
+	if broadMax >= sharpMax {
+		t.Fatalf("Lorentzian broadening did not smooth ImGc0 (max %e to %e)", sharpMax, broadMax)
+	}
+}
+
+func TestUnknownBroadening(t *testing.T) {
+	if _, err := EnvironmentFromString(`{"Broadening":"voigt", "BroadeningWidth":0.1}`); err == nil {
+		t.Fatal("unknown broadening accepted")
+	}
+	if _, err := EnvironmentFromString(`{"Broadening":"gaussian"}`); err == nil {
+		t.Fatal("broadening without width accepted")
+	}
+}
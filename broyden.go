@@ -0,0 +1,280 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Step used for the finite difference Jacobian in SolveSimultaneous, relative
+// to the width of each equation's Range.
+const SimultaneousJacobianStep = 1e-6
+
+// Most times the line search in SolveSimultaneous halves a step before
+// giving up on it.
+const LineSearchMaxHalvings = 30
+
+// Optionally implemented by a TypedEquation: the current value of the
+// variable set by SetArguments, or NaN if it is unknown.  SolveSimultaneous
+// starts from these values; equations without them start from the middle of
+// their Range.
+type ValuedEquation[T any] interface {
+	Value(args T) float64
+}
+
+// Errors returned by SolveSimultaneous and SolveLinearSystem (test with
+// errors.Is).
+var ErrorLineSearch = errors.New("line search failed to reduce the residuals")
+var ErrorSingularMatrix = errors.New("matrix is singular")
+
+// Solve all the equations at once, treating their variables as one vector
+// unknown.  Uses Broyden's method starting from a finite difference Jacobian,
+// with a backtracking line search on the sum of squares of the residuals
+// (each scaled by its tolerance).  The Jacobian is recomputed whenever the
+// Broyden direction fails to reduce the residuals.
+func (system *TypedSystem[T]) SolveSimultaneous(args T) (T, error) {
+	solution, _, err := system.solveSimultaneous(args)
+	return solution, err
+}
+
+// SolveSimultaneous, also returning the history of the solve.  Stops with a
+// SolveError under the same conditions as SolveWithReport.
+func (system *TypedSystem[T]) solveSimultaneous(args T) (T, *SolveReport, error) {
+	report := &SolveReport{}
+	n := len(system.Equations)
+	x := make([]float64, n)
+	for i, eq := range system.Equations {
+		if valued, ok := eq.(ValuedEquation[T]); ok && !math.IsNaN(valued.Value(args)) {
+			x[i] = valued.Value(args)
+			continue
+		}
+		left, right, err := eq.Range(args)
+		if err != nil {
+			return args, report, err
+		}
+		x[i] = 0.5 * (left + right)
+	}
+	var err error
+	x, err = system.clampToRange(x, args)
+	if err != nil {
+		return args, report, err
+	}
+	start := time.Now()
+	args, r := system.scaledResiduals(x, args)
+	jacobian, err := system.jacobian(x, r, args)
+	if err != nil {
+		return args, report, err
+	}
+	fresh := true
+	for !system.residualsSolved(r) {
+		if err := system.checkProgress(report); err != nil {
+			return system.setValues(x, args), report, err
+		}
+		xNew, rNew, ok := system.lineSearch(jacobian, x, r, args)
+		if !ok {
+			if fresh {
+				return system.setValues(x, args), report, ErrorLineSearch
+			}
+			jacobian, err = system.jacobian(x, r, args)
+			if err != nil {
+				return system.setValues(x, args), report, err
+			}
+			fresh = true
+			continue
+		}
+		broydenUpdate(jacobian, vectorDifference(xNew, x), vectorDifference(rNew, r))
+		x, r = xNew, rNew
+		fresh = false
+		residuals := make([]float64, n)
+		for i, ri := range r {
+			residuals[i] = ri * system.residualScale(i)
+		}
+		report.Steps = append(report.Steps, SolveStep{SimultaneousStep, x, residuals, time.Since(start)})
+		start = time.Now()
+	}
+	report.Converged = true
+	return system.setValues(x, args), report, nil
+}
+
+// Set each equation's variable to the corresponding value in x.
+func (system *TypedSystem[T]) setValues(x []float64, args T) T {
+	for i, eq := range system.Equations {
+		args = eq.SetArguments(x[i], args)
+	}
+	return args
+}
+
+// Move each value in x into its equation's Range.
+func (system *TypedSystem[T]) clampToRange(x []float64, args T) ([]float64, error) {
+	clamped := make([]float64, len(x))
+	for i, eq := range system.Equations {
+		left, right, err := eq.Range(args)
+		if err != nil {
+			return nil, err
+		}
+		clamped[i] = math.Min(math.Max(x[i], left), right)
+	}
+	return clamped, nil
+}
+
+// Divisor for the residual of equation i; 1 if it has no tolerance.
+func (system *TypedSystem[T]) residualScale(i int) float64 {
+	if system.Tolerances[i] > 0 {
+		return system.Tolerances[i]
+	}
+	return 1.0
+}
+
+// Set the variables to x and return the residuals, each divided by the
+// corresponding tolerance.
+func (system *TypedSystem[T]) scaledResiduals(x []float64, args T) (T, []float64) {
+	args = system.setValues(x, args)
+	r := make([]float64, len(system.Equations))
+	for i, eq := range system.Equations {
+		r[i] = eq.AbsError(args) / system.residualScale(i)
+	}
+	return args, r
+}
+
+// Are the (scaled) residuals r within tolerance?
+func (system *TypedSystem[T]) residualsSolved(r []float64) bool {
+	for i, ri := range r {
+		if math.Abs(ri)*system.residualScale(i) > system.Tolerances[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Finite difference Jacobian of the scaled residuals at x, where they take
+// the values r.  Element [i][j] is the derivative of residual i with respect
+// to variable j.
+func (system *TypedSystem[T]) jacobian(x, r []float64, args T) ([][]float64, error) {
+	n := len(x)
+	jacobian := make([][]float64, n)
+	for i := range jacobian {
+		jacobian[i] = make([]float64, n)
+	}
+	for j, eq := range system.Equations {
+		left, right, err := eq.Range(args)
+		if err != nil {
+			return nil, err
+		}
+		h := SimultaneousJacobianStep * (right - left)
+		if x[j]+h > right {
+			// step inward from the edge of the range
+			h = -h
+		}
+		shifted := make([]float64, n)
+		copy(shifted, x)
+		shifted[j] += h
+		_, rShifted := system.scaledResiduals(shifted, args)
+		for i := range rShifted {
+			jacobian[i][j] = (rShifted[i] - r[i]) / h
+		}
+	}
+	return jacobian, nil
+}
+
+// Take the step which zeroes the linear model of the residuals, halving it
+// until the sum of squares of the residuals decreases sufficiently.  Returns
+// the new values and residuals, and false if no acceptable step was found.
+func (system *TypedSystem[T]) lineSearch(jacobian [][]float64, x, r []float64, args T) ([]float64, []float64, bool) {
+	negR := make([]float64, len(r))
+	for i, ri := range r {
+		negR[i] = -ri
+	}
+	step, err := SolveLinearSystem(jacobian, negR)
+	if err != nil {
+		return nil, nil, false
+	}
+	merit := dotProduct(r, r)
+	lambda := 1.0
+	for halving := 0; halving < LineSearchMaxHalvings; halving++ {
+		xNew := make([]float64, len(x))
+		for i := range x {
+			xNew[i] = x[i] + lambda*step[i]
+		}
+		xNew, err = system.clampToRange(xNew, args)
+		if err != nil {
+			return nil, nil, false
+		}
+		_, rNew := system.scaledResiduals(xNew, args)
+		// Armijo condition for the full Newton direction
+		if dotProduct(rNew, rNew) <= (1-2e-4*lambda)*merit {
+			return xNew, rNew, true
+		}
+		lambda /= 2
+	}
+	return nil, nil, false
+}
+
+// Broyden's rank one update of jacobian after a step dx which changed the
+// residuals by dr.
+func broydenUpdate(jacobian [][]float64, dx, dr []float64) {
+	norm := dotProduct(dx, dx)
+	if norm == 0 {
+		return
+	}
+	for i, row := range jacobian {
+		mismatch := dr[i] - dotProduct(row, dx)
+		for j := range row {
+			row[j] += mismatch * dx[j] / norm
+		}
+	}
+}
+
+// Solve a x = b by Gaussian elimination with partial pivoting.  a and b are
+// not modified.
+func SolveLinearSystem(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n+1)
+		copy(m[i], a[i])
+		m[i][n] = b[i]
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return nil, ErrorSingularMatrix
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := col + 1; row < n; row++ {
+			factor := m[row][col] / m[col][col]
+			for k := col; k <= n; k++ {
+				m[row][k] -= factor * m[col][k]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := m[row][n]
+		for k := row + 1; k < n; k++ {
+			sum -= m[row][k] * x[k]
+		}
+		x[row] = sum / m[row][row]
+	}
+	return x, nil
+}
+
+func dotProduct(u, v []float64) float64 {
+	sum := 0.0
+	for i := range u {
+		sum += u[i] * v[i]
+	}
+	return sum
+}
+
+func vectorDifference(u, v []float64) []float64 {
+	diff := make([]float64, len(u))
+	for i := range u {
+		diff[i] = u[i] - v[i]
+	}
+	return diff
+}
@@ -0,0 +1,91 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Equation for the variable myVar in [-limit, limit] with residual given by f.
+type CoupledEquation struct {
+	myVar string
+	f     func(vars map[string]float64) float64
+	limit float64
+}
+
+func (eq CoupledEquation) AbsError(args interface{}) float64 {
+	return eq.f(args.(map[string]float64))
+}
+
+func (eq CoupledEquation) SetArguments(x float64, args interface{}) interface{} {
+	vars := args.(map[string]float64)
+	vars[eq.myVar] = x
+	return vars
+}
+
+func (eq CoupledEquation) Range(args interface{}) (float64, float64, error) {
+	return -eq.limit, eq.limit, nil
+}
+
+func TestSolveLinearSystem(t *testing.T) {
+	a := [][]float64{{0, 2, 1}, {1, 1, 1}, {4, -1, 3}}
+	b := []float64{7, 6, 11}
+	x, err := SolveLinearSystem(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []float64{1, 2, 3} {
+		if math.Abs(x[i]-expected) > 1e-12 {
+			t.Fatalf("incorrect solution %v", x)
+		}
+	}
+	if _, err := SolveLinearSystem([][]float64{{1, 2}, {2, 4}}, []float64{1, 1}); !errors.Is(err, ErrorSingularMatrix) {
+		t.Fatalf("singular system accepted")
+	}
+}
+
+// Does SolveSimultaneous solve a strongly coupled nonlinear system?
+func TestSimultaneousCoupled(t *testing.T) {
+	eqU := CoupledEquation{"u", func(v map[string]float64) float64 {
+		return v["u"] - 0.9*v["w"] - 1
+	}, 10.0}
+	eqW := CoupledEquation{"w", func(v map[string]float64) float64 {
+		return v["w"] - 0.9*v["u"] - 0.5*math.Sin(v["u"]*v["w"])
+	}, 10.0}
+	system := &SelfConsistentSystem{Equations: []SelfConsistentEquation{eqU, eqW}, Tolerances: []float64{1e-10, 1e-10}, Simultaneous: true}
+	args := map[string]float64{"u": 0.0, "w": 0.0}
+	solution, err := system.Solve(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !system.IsSolved(solution) {
+		t.Fatalf("system not solved: %v", solution)
+	}
+}
+
+// SolveSimultaneous should reach the same T = 0 solution as the sequential
+// solver.
+func TestSimultaneousZeroTemp(t *testing.T) {
+	tolerances := []float64{1e-9, 1e-9, 1e-9}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	system := NewZeroTempSystem(tolerances)
+	sequential, err := system.Solve(*env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	simultaneous, err := system.SolveSimultaneous(*env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !system.IsSolved(simultaneous) {
+		t.Fatalf("simultaneous solution does not solve the system")
+	}
+	seq, sim := sequential.(Environment), simultaneous.(Environment)
+	if math.Abs(seq.D1-sim.D1) > 1e-7 || math.Abs(seq.Mu-sim.Mu) > 1e-7 || math.Abs(seq.F0-sim.F0) > 1e-7 {
+		t.Fatalf("simultaneous solution\n%s differs from sequential solution\n%s", sim.String(), seq.String())
+	}
+}
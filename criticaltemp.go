@@ -0,0 +1,132 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+)
+
+// Relative distance in beta from beta_c at which to solve the system on
+// either side of the transition.
+const CriticalBetaOffset = 1e-2
+
+// Relative tolerance in beta at which to stop bisecting for beta_c.  Each
+// bisection step is a full solve of the normal state.
+const CriticalBetaTolerance = 1e-9
+
+// Location of the superconducting transition.
+type CriticalPoint struct {
+	Beta  float64     // inverse critical temperature
+	Above Environment // normal state solved just above Tc
+	Below Environment // superconducting state solved just below Tc
+}
+
+// Critical temperature Tc = 1/beta_c.
+func (cp *CriticalPoint) Temperature() float64 {
+	return 1.0 / cp.Beta
+}
+
+// Find beta_c, the point where F0 vanishes.  At each beta in the range
+// (env.CriticalBetaMin, env.CriticalBetaMax), D1 and mu are solved with
+// F0 = 0 and the F0 equation is evaluated; the transition is at the root of
+// this linearized gap equation.  tolerances are given in the order D1, mu, F0.
+func FindCriticalBeta(env Environment, tolerances []float64) (*CriticalPoint, error) {
+	if len(tolerances) != 3 {
+		return nil, errors.New("must give tolerances for D1, mu and F0")
+	}
+	gapError := func(beta float64) (float64, error) {
+		normalEnv, err := solveNormalState(env, beta, tolerances[:2])
+		if err != nil {
+			return 0.0, err
+		}
+		return FiniteTempF0AbsError(normalEnv), nil
+	}
+	betaC, err := scanRoot(gapError, env.CriticalBetaMin, env.CriticalBetaMax)
+	if err != nil {
+		return nil, err
+	}
+	above, err := solveNormalState(env, betaC*(1-CriticalBetaOffset), tolerances[:2])
+	if err != nil {
+		return nil, err
+	}
+	// seed the superconducting solution from the normal state: starting
+	// from the Init values can leave the F0 equation without a bracket
+	belowEnv := above
+	belowEnv.Superconducting = true
+	below, err := NewFiniteTempTypedSystem(betaC*(1+CriticalBetaOffset), tolerances).Solve(belowEnv)
+	if err != nil {
+		return nil, err
+	}
+	return &CriticalPoint{betaC, above, below}, nil
+}
+
+// Solve the D1 and mu equations at inverse temperature beta with F0 = 0.
+func solveNormalState(env Environment, beta float64, tolerances []float64) (Environment, error) {
+	env.F0 = 0.0
+	env.Superconducting = false
+	env.Beta = beta
+	equations := []TypedEquation[Environment]{FiniteTempD1Equation{beta}, normalStateMuEquation{FiniteTempMuEquation{beta}}}
+	system := &TypedSystem[Environment]{Equations: equations, Tolerances: tolerances}
+	return system.Solve(env)
+}
+
+// Mu equation for the normal state.  Unlike FiniteTempMuEquation, mu >= 0 is
+// allowed: with F0 = 0 the pair energy is |xi(k)|, tanh(beta*E/2)/E stays
+// finite as E -> 0, and the normal state needs mu > 0 at low temperature.
+// Factor of 2 is arbitrary, may need to be enlarged for some Environments
+type normalStateMuEquation struct {
+	FiniteTempMuEquation
+}
+
+func (eq normalStateMuEquation) Range(env Environment) (float64, float64, error) {
+	return -2 * env.T0, 2 * env.T0, nil
+}
+
+// Root of f in (left, right).  The first sign change among
+// InitialBracketNumber evenly spaced points is bisected until the bracket is
+// narrower than CriticalBetaTolerance relative to its location.  Unlike
+// FindBracket and BisectionFullPrecision, an error from f stops the search
+// instead of being read as a value of f.
+func scanRoot(f func(float64) (float64, error), left, right float64) (float64, error) {
+	xs := MakeRange(left, right, InitialBracketNumber)
+	lo := xs[0]
+	flo, err := f(lo)
+	if err != nil {
+		return 0.0, err
+	} else if flo == 0.0 {
+		return lo, nil
+	}
+	hi, bracketed := 0.0, false
+	for _, x := range xs[1:] {
+		fx, err := f(x)
+		if err != nil {
+			return 0.0, err
+		}
+		if fx == 0.0 {
+			return x, nil
+		}
+		if !sameSign(flo, fx) {
+			hi, bracketed = x, true
+			break
+		}
+		lo, flo = x, fx
+	}
+	if !bracketed {
+		return 0.0, ErrorNoBracket
+	}
+	for hi-lo > CriticalBetaTolerance*math.Max(math.Abs(lo), math.Abs(hi)) {
+		mid := (lo + hi) / 2.0
+		fmid, err := f(mid)
+		if err != nil {
+			return 0.0, err
+		}
+		if fmid == 0.0 {
+			return mid, nil
+		}
+		if sameSign(flo, fmid) {
+			lo, flo = mid, fmid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2.0, nil
+}
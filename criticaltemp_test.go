@@ -0,0 +1,61 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Does FindCriticalBeta find a point where the linearized gap equation is
+// solved, with a superconducting solution below Tc and a normal one above?
+func TestFindCriticalBeta(t *testing.T) {
+	tolerances := []float64{1e-6, 1e-6, 1e-6}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	env.CriticalBetaMin, env.CriticalBetaMax = 1.0, 5.0
+	cp, err := FindCriticalBeta(*env, tolerances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Beta < env.CriticalBetaMin || cp.Beta > env.CriticalBetaMax {
+		t.Fatalf("beta_c (%f) outside of scanned range", cp.Beta)
+	}
+	atTc, err := solveNormalState(*env, cp.Beta, tolerances[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(FiniteTempF0AbsError(atTc)) > 1e-6 {
+		t.Fatalf("F0 equation not solved at beta_c (error %e)", FiniteTempF0AbsError(atTc))
+	}
+	if cp.Above.F0 != 0.0 || cp.Below.F0 <= 0.0 {
+		t.Fatalf("unexpected order parameter near Tc: above %f, below %f", cp.Above.F0, cp.Below.F0)
+	}
+}
+
+// Does a failed evaluation stop the search for beta_c instead of being
+// mistaken for a root?
+func TestScanRootStopsOnError(t *testing.T) {
+	failed := errors.New("solve failed")
+	f := func(x float64) (float64, error) {
+		if x > 2.0 {
+			return 0.0, failed
+		}
+		return x - 3.0, nil
+	}
+	if _, err := scanRoot(f, 1.0, 5.0); !errors.Is(err, failed) {
+		t.Fatalf("expected solve error, got %v", err)
+	}
+	g := func(x float64) (float64, error) {
+		return x - 3.0, nil
+	}
+	root, err := scanRoot(g, 1.0, 5.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(root-3.0) > 3.0*CriticalBetaTolerance {
+		t.Fatalf("incorrect root %v", root)
+	}
+}
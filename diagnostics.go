@@ -0,0 +1,198 @@
+package polecalc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Sum rule and Kramers-Kronig checks of Gc0 and the full G at one k.
+// The spectral sum rule \int A(k, omega) d omega = 1 is checked for the full G,
+// with A the continuum of -ImG/pi plus the isolated quasiparticle poles.  The
+// moments are also checked against the unbinned pole terms of ImGc0: the
+// binned Gc0 should have their weight M0 and first moment M1, and the full
+// G = 1/(1/Gc0 - epsilon_k) weight M0 and first moment M1 + epsilon_k*M0^2.
+// All Green's function values use the scale of the binned ImGc0 (weight per
+// bin), as in ZeroTempReGc0 and FullImGc; PoleWeight and PoleFirstMoment are
+// converted to that scale.
+type GreenDiagnostics struct {
+	K                           Vector2
+	PoleWeight, PoleFirstMoment float64 // \int A0 and \int omega*A0 from the unbinned pole terms
+	Gc0Weight, Gc0FirstMoment   float64 // same moments of the binned ImGc0
+	FullWeight, FullFirstMoment float64 // continuum of -ImG/pi plus isolated quasiparticle poles
+	KramersKronigError          float64 // max |ImGc0 rebuilt from ReGc0 - ImGc0|, relative to max |ImGc0|
+	Epsilon                     float64 // electron energy at K
+}
+
+// Absolute violation of each sum rule: \int A = 1 for the full G, then the
+// Gc0 weight and first moment, then the full G weight and first moment
+// (M1 + epsilon_k*M0^2), each against the pole terms.
+func (gd *GreenDiagnostics) Violations() []float64 {
+	shift := gd.Epsilon * gd.PoleWeight * gd.PoleWeight
+	return []float64{math.Abs(gd.FullWeight - 1.0),
+		math.Abs(gd.Gc0Weight - gd.PoleWeight),
+		math.Abs(gd.Gc0FirstMoment - gd.PoleFirstMoment),
+		math.Abs(gd.FullWeight - gd.PoleWeight),
+		math.Abs(gd.FullFirstMoment - (gd.PoleFirstMoment + shift))}
+}
+
+// Are all the sum rules satisfied to within tolerance and the KK rebuild of
+// ImGc0 within kkTolerance?
+func (gd *GreenDiagnostics) Passes(tolerance, kkTolerance float64) bool {
+	for _, v := range gd.Violations() {
+		if v > tolerance {
+			return false
+		}
+	}
+	return gd.KramersKronigError <= kkTolerance
+}
+
+func (gd *GreenDiagnostics) String() string {
+	v := gd.Violations()
+	return fmt.Sprintf("k: %v; sum rule: %e; Gc0 weight: %e, moment: %e; G weight: %e, moment: %e; KK: %e", gd.K, v[0], v[1], v[2], v[3], v[4], gd.KramersKronigError)
+}
+
+// Run all checks at each k in ks.
+func ZeroTempGreenDiagnostics(env Environment, ks []Vector2) ([]*GreenDiagnostics, error) {
+	reports := make([]*GreenDiagnostics, len(ks))
+	for i, k := range ks {
+		report, err := zeroTempGreenDiagnosticsPoint(env, k)
+		if err != nil {
+			return nil, err
+		}
+		reports[i] = report
+	}
+	return reports, nil
+}
+
+func zeroTempGreenDiagnosticsPoint(env Environment, k Vector2) (*GreenDiagnostics, error) {
+	omegas, imValues := ZeroTempImGc0(env, k)
+	if len(omegas) < 5 {
+		return nil, errors.New("need at least five ImGc0 bins for diagnostics")
+	}
+	binWidth := omegas[1] - omegas[0]
+	omegaMin, omegaMax := omegas[0], omegas[len(omegas)-1]
+	gd := &GreenDiagnostics{K: k}
+	gd.Epsilon = ZeroTempElectronEnergy(env, k)
+	// pole-term moments, in the scale of the binned values
+	poleWeight, poleMoment := deltaTermMoments(env, k)
+	gd.PoleWeight, gd.PoleFirstMoment = poleWeight*binWidth, poleMoment*binWidth
+	// binned Gc0 moments: integrate the spline of ImGc0
+	weights := make([]float64, len(omegas))
+	moments := make([]float64, len(omegas))
+	for i, omega := range omegas {
+		weights[i] = -imValues[i] / math.Pi
+		moments[i] = omega * weights[i]
+	}
+	var err error
+	if gd.Gc0Weight, err = SplineIntegral(omegas, weights, omegaMin, omegaMax); err != nil {
+		return nil, err
+	}
+	if gd.Gc0FirstMoment, err = SplineIntegral(omegas, moments, omegaMin, omegaMax); err != nil {
+		return nil, err
+	}
+	// full G continuum on the bin grid plus isolated poles.  ReGc0 can't be
+	// evaluated on the edges of the ImGc0 range, but there ImGc0 = 0 (the
+	// range is padded) so they are left out.
+	inner, innerIm := omegas[1:len(omegas)-1], imValues[1:len(omegas)-1]
+	reValues := make([]float64, len(inner))
+	fullWeights := make([]float64, len(inner))
+	fullMoments := make([]float64, len(inner))
+	for i, omega := range inner {
+		if reValues[i], err = ZeroTempReGc0(env, k, omega); err != nil {
+			return nil, err
+		}
+		fullWeights[i] = -fullImGcFromGc0(gd.Epsilon, reValues[i], innerIm[i]) / math.Pi
+		fullMoments[i] = omega * fullWeights[i]
+	}
+	innerMin, innerMax := inner[0], inner[len(inner)-1]
+	if gd.FullWeight, err = SplineIntegral(inner, fullWeights, innerMin, innerMax); err != nil {
+		return nil, err
+	}
+	if gd.FullFirstMoment, err = SplineIntegral(inner, fullMoments, innerMin, innerMax); err != nil {
+		return nil, err
+	}
+	poles, err := ZeroTempGreenPolePoint(env, k)
+	if err != nil && !errors.Is(err, ErrorNoBracket) {
+		return nil, err
+	}
+	for _, p := range poles {
+		if isolatedPole(omegas, imValues, p.Omega) {
+			gd.FullWeight += p.Residue
+			gd.FullFirstMoment += p.Omega * p.Residue
+		}
+	}
+	// rebuild ImGc0 from the spline of ReGc0
+	gd.KramersKronigError, err = kramersKronigError(env, inner, innerIm, reValues)
+	if err != nil {
+		return nil, err
+	}
+	return gd, nil
+}
+
+// \int A0 and \int omega*A0 summed directly over the pole terms of ImGc0.
+func deltaTermMoments(env Environment, k Vector2) (float64, float64) {
+	weightWorker := func(q Vector2) float64 {
+		_, coeffs := deltaTermsGc0(env, k, q)
+		weight := 0.0
+		for _, c := range coeffs {
+			weight -= c / math.Pi
+		}
+		return weight
+	}
+	momentWorker := func(q Vector2) float64 {
+		omegas, coeffs := deltaTermsGc0(env, k, q)
+		moment := 0.0
+		for i, c := range coeffs {
+			moment -= omegas[i] * c / math.Pi
+		}
+		return moment
+	}
+	return Average(env.GridLength, weightWorker), Average(env.GridLength, momentWorker)
+}
+
+// ImGc0 smaller than this fraction of max |ImGc0| is treated as 0 when
+// deciding whether a pole is isolated (broadening leaves small tails
+// everywhere).
+const IsolatedPoleThreshold = 1e-3
+
+// Is the pole at omega outside the support of ImGc0 (so that it is a delta
+// function in A rather than a resonance already counted in the continuum)?
+func isolatedPole(omegas, imValues []float64, omega float64) bool {
+	maxIm := 0.0
+	for _, im := range imValues {
+		maxIm = math.Max(maxIm, math.Abs(im))
+	}
+	binWidth := omegas[1] - omegas[0]
+	i := int(math.Floor((omega - omegas[0]) / binWidth))
+	for j := i - 1; j <= i+2; j++ {
+		if j >= 0 && j < len(imValues) && math.Abs(imValues[j]) > IsolatedPoleThreshold*maxIm {
+			return false
+		}
+	}
+	return true
+}
+
+// Max over interior bins of |ImaginaryFromReal[ReGc0] - ImGc0|, relative to
+// max |ImGc0|.
+func kramersKronigError(env Environment, omegas, imValues, reValues []float64) (float64, error) {
+	reSpline, err := NewCubicSpline(omegas, reValues)
+	if err != nil {
+		return 0.0, err
+	}
+	omegaMin, omegaMax := reSpline.Range()
+	rebuilt := ImaginaryFromReal(reSpline.At, omegaMin, omegaMax, env.ReGc0dw, env.ReGc0Points)
+	maxDiff, maxIm := 0.0, 0.0
+	for i := 1; i < len(omegas)-1; i++ {
+		im, err := rebuilt(omegas[i])
+		if err != nil {
+			return 0.0, err
+		}
+		maxDiff = math.Max(maxDiff, math.Abs(im-imValues[i]))
+		maxIm = math.Max(maxIm, math.Abs(imValues[i]))
+	}
+	if maxIm == 0.0 {
+		return maxDiff, nil
+	}
+	return maxDiff / maxIm, nil
+}
@@ -0,0 +1,77 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// Binning conserves the weight of the pole terms, so the Gc0 weight should
+// match it to rounding error at any number of bins.
+func TestDiagnosticsGc0Weight(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength, env.ImGc0Bins, env.ReGc0Points, env.ReGc0dw = 16, 64, 64, 1e-4
+	reports, err := ZeroTempGreenDiagnostics(*env, []Vector2{Vector2{0.3, 1.1}, Vector2{-2.0, 0.5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range reports {
+		if v := r.Violations()[1]; v > 1e-6*r.PoleWeight {
+			t.Fatalf("Gc0 weight not conserved (%s)", r.String())
+		}
+	}
+}
+
+// Using more points in the ReGc0 integral should bring the full G weight and
+// the Kramers-Kronig rebuild of ImGc0 closer to their exact values.
+func TestDiagnosticsConvergeWithReGc0Points(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength, env.ImGc0Bins, env.ReGc0dw = 16, 64, 1e-4
+	k := []Vector2{Vector2{0.3, 1.1}}
+	env.ReGc0Points = 64
+	coarse, err := ZeroTempGreenDiagnostics(*env, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.ReGc0Points = 512
+	fine, err := ZeroTempGreenDiagnostics(*env, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, f := coarse[0], fine[0]
+	if math.Abs(f.Violations()[3]) >= math.Abs(c.Violations()[3]) || f.KramersKronigError >= c.KramersKronigError {
+		t.Fatalf("diagnostics did not improve with ReGc0Points: coarse (%s), fine (%s)", c.String(), f.String())
+	}
+}
+
+// A G with unit weight and moments matching its pole terms passes; taking
+// weight away breaks the sum rule.
+func TestDiagnosticsSumRule(t *testing.T) {
+	gd := GreenDiagnostics{PoleWeight: 1.0, PoleFirstMoment: 0.2, Gc0Weight: 1.0, Gc0FirstMoment: 0.2,
+		FullWeight: 1.0, FullFirstMoment: 0.7, Epsilon: 0.5}
+	if !gd.Passes(1e-12, 0.0) {
+		t.Fatalf("consistent diagnostics failed (%s)", gd.String())
+	}
+	gd.FullWeight = 0.75
+	if v := gd.Violations()[0]; math.Abs(v-0.25) > 1e-12 || gd.Passes(1e-12, 0.0) {
+		t.Fatalf("sum rule violation not reported (%s)", gd.String())
+	}
+}
+
+// A pole in the small tail of a broadened ImGc0 is still isolated; one next
+// to the continuum is not.
+func TestIsolatedPole(t *testing.T) {
+	omegas := []float64{0.0, 1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0}
+	imValues := []float64{-1.0, -0.5, -1e-2, -1e-6, -1e-7, -1e-7, -1e-6, -1e-5}
+	if !isolatedPole(omegas, imValues, 5.5) {
+		t.Fatal("pole in the broadening tail not isolated")
+	}
+	if isolatedPole(omegas, imValues, 1.5) {
+		t.Fatal("pole inside the continuum isolated")
+	}
+}
@@ -0,0 +1,131 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+)
+
+// A band energy as a function of k.  The Environment supplies whatever
+// hopping parameters the model needs.
+type Dispersion interface {
+	Energy(env Environment, k Vector2) float64
+}
+
+// Names used to select a Dispersion in Environment.HoleModel and
+// Environment.ElectronModel.  An empty name selects the default model for
+// that band (HolonModel for holes, TightBindingModel for electrons).
+// TableModel has fixed hoppings, so as the hole model it ignores D1 and the
+// D1 equation no longer feeds back into the holon band.
+const (
+	HolonModel        = "holon"
+	TightBindingModel = "t-t'-t''"
+	TableModel        = "table"
+)
+
+// Holon hopping with the two-hole D1 renormalization of the diagonal term:
+// 2Th((sx+sy)^2 - 1) + 4(D1*T0 - Thp)sx*sy
+type HolonDispersion struct{}
+
+func (d HolonDispersion) Energy(env Environment, k Vector2) float64 {
+	sx, sy := math.Sin(k.X), math.Sin(k.Y)
+	return 2*env.Th()*((sx+sy)*(sx+sy)-1) + 4*(env.D1*env.T0-env.Thp)*sx*sy
+}
+
+// Electron t, tp, tpp band:
+// -2T(cx + cy) - 4Tp*cx*cy - 2Tpp(cos(2kx) + cos(2ky))
+// With Tp = Tpp = 0 this is the nearest-neighbor band.
+type TightBindingDispersion struct{}
+
+func (d TightBindingDispersion) Energy(env Environment, k Vector2) float64 {
+	cx, cy := math.Cos(k.X), math.Cos(k.Y)
+	c2x, c2y := math.Cos(2*k.X), math.Cos(2*k.Y)
+	return -2*env.T*(cx+cy) - 4*env.Tp*cx*cy - 2*env.Tpp*(c2x+c2y)
+}
+
+// Hopping amplitude T to the site at lattice vector (X, Y).
+type Hopping struct {
+	X, Y int
+	T    float64
+}
+
+// Band built from a table of hoppings: -2 \sum_R T_R cos(k.R).
+// Give only one of each pair of vectors R, -R.  Doesn't depend on D1.
+type TableDispersion []Hopping
+
+func (d TableDispersion) Energy(env Environment, k Vector2) float64 {
+	energy := 0.0
+	for _, h := range d {
+		energy -= 2 * h.T * math.Cos(float64(h.X)*k.X+float64(h.Y)*k.Y)
+	}
+	return energy
+}
+
+// Dispersion used for the holon (Epsilon, Xi, and everything built on them)
+func (env *Environment) HoleDispersion() Dispersion {
+	if env.holeDispersion.matches(env.HoleModel, env.HoleHoppings) {
+		return env.holeDispersion.model
+	}
+	d, err := dispersionByName(env.HoleModel, HolonModel, env.HoleHoppings)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Dispersion used for the physical electron (ZeroTempElectronEnergy)
+func (env *Environment) ElectronDispersion() Dispersion {
+	if env.electronDispersion.matches(env.ElectronModel, env.ElectronHoppings) {
+		return env.electronDispersion.model
+	}
+	d, err := dispersionByName(env.ElectronModel, TightBindingModel, env.ElectronHoppings)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// A Dispersion found by ResolveModels, with the name and hopping table it
+// was found from.  The table is copied so that changes to the Environment's
+// table are noticed.
+type resolvedDispersion struct {
+	name     string
+	hoppings []Hopping
+	model    Dispersion
+}
+
+func resolveDispersion(name, defaultName string, hoppings []Hopping) (resolvedDispersion, error) {
+	table := append([]Hopping(nil), hoppings...)
+	model, err := dispersionByName(name, defaultName, table)
+	if err != nil {
+		return resolvedDispersion{}, err
+	}
+	return resolvedDispersion{name, table, model}, nil
+}
+
+// Was r resolved from name and hoppings?
+func (r resolvedDispersion) matches(name string, hoppings []Hopping) bool {
+	if r.model == nil || r.name != name || len(r.hoppings) != len(hoppings) {
+		return false
+	}
+	for i, h := range hoppings {
+		if r.hoppings[i] != h {
+			return false
+		}
+	}
+	return true
+}
+
+func dispersionByName(name, defaultName string, hoppings []Hopping) (Dispersion, error) {
+	if name == "" {
+		name = defaultName
+	}
+	switch name {
+	case HolonModel:
+		return HolonDispersion{}, nil
+	case TightBindingModel:
+		return TightBindingDispersion{}, nil
+	case TableModel:
+		return TableDispersion(hoppings), nil
+	}
+	return nil, errors.New("unknown dispersion model " + name)
+}
@@ -0,0 +1,86 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// A hopping table with the t, tp, tpp vectors should reproduce the built-in
+// tight-binding band.
+func TestTableDispersionMatchesTightBinding(t *testing.T) {
+	jsonData := `{"GridLength":8, "T":0.4, "Tp":-0.1, "Tpp":0.05,
+		"HoleModel":"table", "HoleHoppings":[{"X":1, "Y":0, "T":0.4},
+		{"X":0, "Y":1, "T":0.4}, {"X":1, "Y":1, "T":-0.1},
+		{"X":1, "Y":-1, "T":-0.1}, {"X":2, "Y":0, "T":0.05},
+		{"X":0, "Y":2, "T":0.05}]}`
+	env, err := EnvironmentFromString(jsonData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker := func(k Vector2) float64 {
+		return math.Abs(EpsilonBar(*env, k) - ZeroTempElectronEnergy(*env, k))
+	}
+	if diff := Maximum(env.GridLength, worker); diff > 1e-12 {
+		t.Fatalf("table dispersion differs from tight-binding by %e", diff)
+	}
+}
+
+// The default models should give the original hole and electron bands.
+func TestDefaultDispersions(t *testing.T) {
+	env, err := EnvironmentFromFile("environment_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	env.T = 0.3
+	worker := func(k Vector2) float64 {
+		sx, sy := math.Sin(k.X), math.Sin(k.Y)
+		hole := 2*env.Th()*((sx+sy)*(sx+sy)-1) + 4*(env.D1*env.T0-env.Thp)*sx*sy
+		electron := -2.0 * env.T * (math.Cos(k.X) + math.Cos(k.Y))
+		return math.Abs(EpsilonBar(*env, k)-hole) + math.Abs(ZeroTempElectronEnergy(*env, k)-electron)
+	}
+	if diff := Maximum(env.GridLength, worker); diff > 1e-12 {
+		t.Fatalf("default dispersions changed by %e", diff)
+	}
+}
+
+func TestUnknownDispersion(t *testing.T) {
+	_, err := EnvironmentFromString(`{"ElectronModel":"no such model"}`)
+	if err == nil {
+		t.Fatal("unknown dispersion model accepted")
+	}
+}
+
+// Changing a model name or hopping table after Initialize should change the
+// model used.
+func TestDispersionFollowsModelChanges(t *testing.T) {
+	env, err := EnvironmentFromFile("environment_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	k := Vector2{0.3, 1.1}
+	env.HoleModel = TableModel
+	env.HoleHoppings = []Hopping{{1, 0, 0.5}}
+	if e, expected := EpsilonBar(*env, k), -math.Cos(k.X); math.Abs(e-expected) > 1e-12 {
+		t.Fatalf("new hole model not used (got %f, expected %f)", e, expected)
+	}
+	env.HoleHoppings[0].T = 1.0
+	if e, expected := EpsilonBar(*env, k), -2*math.Cos(k.X); math.Abs(e-expected) > 1e-12 {
+		t.Fatalf("changed hopping table not used (got %f, expected %f)", e, expected)
+	}
+	if err := env.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	env.GapModel = DX2Y2GapModel
+	re, _ := env.Gap().FormFactor(*env, k)
+	if expected := math.Cos(k.X) - math.Cos(k.Y); re != expected {
+		t.Fatalf("new gap model not used (got %f, expected %f)", re, expected)
+	}
+	env.ElectronModel = "no such model"
+	if err := env.Initialize(); err == nil {
+		t.Fatal("Initialize accepted an unknown model")
+	}
+}
@@ -0,0 +1,123 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+)
+
+// --- electron density of states ---
+
+// ImGc0 at every k on the GridLength mesh.  All k share one set of omega bins
+// and (for HistogramMethod) are filled in a single pass over q.  Returns the k
+// points, the omega values of the bins, and the ImGc0 values indexed as
+// [k][omega].
+func ZeroTempImGc0AllK(env Environment) ([]Vector2, []float64, [][]float64) {
+	omegaMin, omegaMax := imGc0OmegaRange(env, Vector2{0.0, 0.0})
+	ks, _ := collectKs(func(callback Callback) error {
+		return CallOnPlane(env.GridLength, callback)
+	})
+	deltaTermsAt := func(k Vector2) DeltaTermsFunc {
+		return func(q Vector2) ([]float64, []float64) {
+			return deltaTermsGc0(env, k, q)
+		}
+	}
+	if env.ImGc0Method == TriangleMethod {
+		values := make([][]float64, len(ks))
+		for i, k := range ks {
+			values[i] = TriangleBin(env.GridLength, deltaTermsAt(k), omegaMin, omegaMax, env.ImGc0Bins)
+		}
+		return ks, BinVarValues(omegaMin, omegaMax, env.ImGc0Bins), values
+	}
+	binners := make([]*DeltaBinner, len(ks))
+	for i, k := range ks {
+		binners[i] = NewBroadenedDeltaBinner(deltaTermsAt(k), omegaMin, omegaMax, env.ImGc0Bins, env.BroadeningKernel())
+	}
+	values := MultiDeltaBin(env.GridLength, NewMultiDeltaBinner(binners))
+	return ks, binners[0].BinVarValues(), values
+}
+
+// N0(omega) = -1/(pi N) \sum_k ImGc0(k, omega)
+// Values are in the same units as ZeroTempImGc0 (weight per bin).
+// Returns omega values and the corresponding density of states.
+func ZeroTempGc0DOS(env Environment) ([]float64, []float64) {
+	_, omegas, imValues := ZeroTempImGc0AllK(env)
+	dos := make([]float64, len(omegas))
+	for j, _ := range omegas {
+		sum, compensate := 0.0, 0.0
+		for i, _ := range imValues {
+			sum, compensate = KahanSum(imValues[i][j], sum, compensate)
+		}
+		dos[j] = -sum / (math.Pi * float64(len(imValues)))
+	}
+	return omegas, dos
+}
+
+// N(omega) = -1/(pi N) \sum_k ImG(k, omega) for the full Green's function,
+// evaluated at the given omegas.  ImGc0 for every k comes from
+// ZeroTempImGc0AllK; ReGc0 is found from it with a principal value integral,
+// so omegas must not lie exactly on the edge of the ImGc0 range.
+func ZeroTempFullDOS(env Environment, omegas []float64) ([]float64, error) {
+	ks, imOmegas, imValues := ZeroTempImGc0AllK(env)
+	sums, compensates := make([]float64, len(omegas)), make([]float64, len(omegas))
+	for i, k := range ks {
+		imPart, err := NewCubicSpline(imOmegas, imValues[i])
+		if err != nil {
+			return nil, err
+		}
+		for j, omega := range omegas {
+			ReGc0, err := splineReGc0(env, imPart, omega)
+			if err != nil {
+				return nil, err
+			}
+			ImGc0, err := splineImGc0Point(imPart, omega)
+			if err != nil {
+				return nil, err
+			}
+			ImGc := fullImGcFromGc0(ZeroTempElectronEnergy(env, k), ReGc0, ImGc0)
+			if math.IsNaN(ImGc) {
+				// pole exactly on the real axis; can't resolve it here
+				continue
+			}
+			sums[j], compensates[j] = KahanSum(ImGc, sums[j], compensates[j])
+		}
+	}
+	dos := make([]float64, len(omegas))
+	for j, sum := range sums {
+		dos[j] = -sum / (math.Pi * float64(len(ks)))
+	}
+	return dos, nil
+}
+
+// Find the edges of the gap around omega = 0 in the density of states: the
+// omega values closest to 0 on either side where dos exceeds threshold.
+// omegas must be in ascending order.
+func DOSGapEdges(omegas, dos []float64, threshold float64) (float64, float64, error) {
+	if len(omegas) != len(dos) {
+		return 0.0, 0.0, errors.New("omegas and dos must be the same length")
+	}
+	// first index with omega >= 0
+	zero := len(omegas)
+	for i, omega := range omegas {
+		if omega >= 0 {
+			zero = i
+			break
+		}
+	}
+	lower, upper := -1, -1
+	for i := zero - 1; i >= 0; i-- {
+		if dos[i] > threshold {
+			lower = i
+			break
+		}
+	}
+	for i := zero; i < len(omegas); i++ {
+		if dos[i] > threshold {
+			upper = i
+			break
+		}
+	}
+	if lower == -1 || upper == -1 {
+		return 0.0, 0.0, errors.New("density of states does not exceed threshold on both sides of omega = 0")
+	}
+	return omegas[lower], omegas[upper], nil
+}
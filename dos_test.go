@@ -0,0 +1,83 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// Does binning every k at once give the same density of states as averaging
+// ZeroTempImGc0 over k one point at a time?
+func TestDOSGc0MatchesPerK(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 8
+	env.ImGc0Bins = 64
+	omegas, dos := ZeroTempGc0DOS(*env)
+	expected := make([]float64, len(omegas))
+	callback := func(k Vector2) error {
+		_, imValues := ZeroTempImGc0(*env, k)
+		for j, im := range imValues {
+			expected[j] -= im / (math.Pi * 64)
+		}
+		return nil
+	}
+	CallOnPlane(env.GridLength, callback)
+	for j, omega := range omegas {
+		if math.Abs(dos[j]-expected[j]) > 1e-12 {
+			t.Fatalf("DOS mismatch at omega = %f (got %e, expected %e)", omega, dos[j], expected[j])
+		}
+	}
+}
+
+// Does the full-G density of states match an average of FullImGc over k?
+func TestDOSFullMatchesPerK(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 8
+	env.ImGc0Bins = 64
+	env.ReGc0Points = 64
+	omegas := []float64{-2.5, 0.5, 3.5}
+	dos, err := ZeroTempFullDOS(*env, omegas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, omega := range omegas {
+		expected := 0.0
+		callback := func(k Vector2) error {
+			ImGc, err := FullImGc(*env, k, omega)
+			if err != nil {
+				return err
+			}
+			expected -= ImGc / (math.Pi * 64)
+			return nil
+		}
+		if err := CallOnPlane(env.GridLength, callback); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(dos[j]-expected) > 1e-9*math.Max(1, math.Abs(expected)) {
+			t.Fatalf("DOS mismatch at omega = %f (got %e, expected %e)", omega, dos[j], expected)
+		}
+	}
+}
+
+// Are gap edges found for a DOS which vanishes on (-1, 2)?
+func TestDOSGapEdges(t *testing.T) {
+	omegas := MakeRange(-3.0, 3.0, 13)
+	dos := make([]float64, len(omegas))
+	for i, omega := range omegas {
+		if omega <= -1.0 || omega >= 2.0 {
+			dos[i] = 1.0
+		}
+	}
+	lower, upper, err := DOSGapEdges(omegas, dos, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lower != -1.0 || upper != 2.0 {
+		t.Fatalf("incorrect gap edges (got %f, %f)", lower, upper)
+	}
+}
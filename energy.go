@@ -0,0 +1,118 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Maximum number of D1 updates in solveZeroTempNormalState.
+const NormalStateMaxIterations = 1000
+
+// Mean-field ground-state energy per site at T = 0:
+// E = 1/N \sum_k (xi(k) - E(k))/2 + 4(t0+tz)F0^2 + 2t0*D1^2 + (mu + epsilonMin)x
+// The first two terms are the BCS grand potential, the D1 term is the
+// decoupling cost of the two-hole diagonal hopping, and the last term
+// converts from fixed chemical potential to fixed density.  Each of the D1
+// and F0 equations is the condition that E is stationary in that variable.
+func FreeEnergy(env Environment) float64 {
+	worker := func(k Vector2) float64 {
+		return 0.5 * (Xi(env, k) - ZeroTempPairEnergy(env, k))
+	}
+	band := Average(env.GridLength, worker)
+	pairing := 4 * (env.T0 + env.Tz) * env.F0 * env.F0
+	hopping := 2 * env.T0 * env.D1 * env.D1
+	return band + pairing + hopping + (env.Mu+env.EpsilonMin)*env.X
+}
+
+// Normal and superconducting solutions at the same parameters.
+type PhaseComparison struct {
+	Normal, Superconducting             Environment
+	NormalEnergy, SuperconductingEnergy float64
+}
+
+// E_normal - E_superconducting: positive if the superconducting phase is
+// favoured.
+func (pc *PhaseComparison) CondensationEnergy() float64 {
+	return pc.NormalEnergy - pc.SuperconductingEnergy
+}
+
+func (pc *PhaseComparison) SuperconductingFavoured() bool {
+	return pc.CondensationEnergy() > 0.0
+}
+
+// Solve the T = 0 system in both phases and compare their energies.
+// tolerances are given in the order D1, mu, F0; the normal state uses only
+// the D1 tolerance.
+func ComparePhases(env Environment, tolerances []float64) (*PhaseComparison, error) {
+	if len(tolerances) != 3 {
+		return nil, errors.New("must give tolerances for D1, mu and F0")
+	}
+	scEnv := env
+	scEnv.Superconducting = true
+	if err := scEnv.Initialize(); err != nil {
+		return nil, err
+	}
+	sc, err := NewZeroTempTypedSystem(tolerances).Solve(scEnv)
+	if err != nil {
+		return nil, err
+	}
+	normalEnv := env
+	if err := normalEnv.Initialize(); err != nil {
+		return nil, err
+	}
+	normal, err := solveZeroTempNormalState(normalEnv, tolerances[0])
+	if err != nil {
+		return nil, err
+	}
+	return &PhaseComparison{normal, sc, FreeEnergy(normal), FreeEnergy(sc)}, nil
+}
+
+// Solve the T = 0 normal state (F0 = 0).  The mu equation is then a
+// staircase on a finite grid, so instead of bisecting in mu the lowest
+// x*N holon states are filled directly (with a fractional occupation for the
+// last one) and D1 is iterated to self-consistency.
+func solveZeroTempNormalState(env Environment, tolerance float64) (Environment, error) {
+	env.F0 = 0.0
+	env.Superconducting = false
+	L := env.GridLength
+	N := uint64(L) * uint64(L)
+	ks := make([]Vector2, N)
+	for i := uint64(0); i < N; i++ {
+		ks[i] = SquareAt(i, L)
+	}
+	for iter := 0; iter < NormalStateMaxIterations; iter++ {
+		energies := make([]float64, N)
+		for i, k := range ks {
+			energies[i] = EpsilonBar(env, k)
+		}
+		order := make([]int, N)
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(a, b int) bool {
+			return energies[order[a]] < energies[order[b]]
+		})
+		// occupy states from the bottom of the band until x is reached
+		remaining := env.X * float64(N)
+		sinSum, muBar := 0.0, energies[order[0]]
+		for _, i := range order {
+			if remaining <= 0.0 {
+				break
+			}
+			n := math.Min(remaining, 1.0)
+			sinSum += n * math.Sin(ks[i].X) * math.Sin(ks[i].Y)
+			muBar = energies[i]
+			remaining -= n
+		}
+		D1 := -sinSum / float64(N)
+		converged := math.Abs(D1-env.D1) <= tolerance
+		env.D1 = D1
+		env.EpsilonMin = EpsilonMin(env)
+		env.Mu = muBar - env.EpsilonMin
+		if converged {
+			return env, nil
+		}
+	}
+	return env, errors.New("normal state D1 did not converge")
+}
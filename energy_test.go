@@ -0,0 +1,70 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// At a solution of the T = 0 system, FreeEnergy should be stationary in F0
+// and D1 with the absolute chemical potential mu + epsilonMin held fixed.
+func TestFreeEnergyStationary(t *testing.T) {
+	tolerances := []float64{1e-9, 1e-9, 1e-9}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	solution, err := NewZeroTempSystem(tolerances).Solve(*env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solved := solution.(Environment)
+	muBar := solved.Mu + solved.EpsilonMin
+	h := 1e-4
+	shifted := func(dD1, dF0 float64) float64 {
+		s := solved
+		s.D1 += dD1
+		s.F0 += dF0
+		s.EpsilonMin = EpsilonMin(s)
+		s.Mu = muBar - s.EpsilonMin
+		return FreeEnergy(s)
+	}
+	dF0 := (shifted(0, h) - shifted(0, -h)) / (2 * h)
+	dD1 := (shifted(h, 0) - shifted(-h, 0)) / (2 * h)
+	if math.Abs(dF0) > 1e-6 || math.Abs(dD1) > 1e-6 {
+		t.Fatalf("FreeEnergy not stationary at solution (dE/dF0 = %e, dE/dD1 = %e)", dF0, dD1)
+	}
+}
+
+// The normal state should satisfy the D1 equation and hold x holons; at the
+// test parameters the superconducting state should have lower energy.
+func TestComparePhases(t *testing.T) {
+	tolerances := []float64{1e-6, 1e-6, 1e-6}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := ComparePhases(*env, tolerances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normal := pc.Normal
+	occupation := func(k Vector2) float64 {
+		if Xi(normal, k) < 0.0 {
+			return 1.0
+		}
+		return 0.0
+	}
+	if filled := Average(normal.GridLength, occupation); math.Abs(filled-normal.X) > 1.0/float64(normal.GridLength*normal.GridLength) {
+		t.Fatalf("normal state holds %f holons; expected %f", filled, normal.X)
+	}
+	d1Worker := func(k Vector2) float64 {
+		return -occupation(k) * math.Sin(k.X) * math.Sin(k.Y)
+	}
+	if D1 := Average(normal.GridLength, d1Worker); math.Abs(D1-normal.D1) > 1e-2 {
+		t.Fatalf("normal state D1 = %f inconsistent with occupation (%f)", normal.D1, D1)
+	}
+	if !pc.SuperconductingFavoured() {
+		t.Fatalf("superconducting state not favoured (condensation energy %f)", pc.CondensationEnergy())
+	}
+}
@@ -3,6 +3,7 @@ package polecalc
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -16,28 +17,53 @@ type Environment struct {
 	ImGc0Bins   uint    // number of bins to use when calculating the imaginary part of the electron Green's function
 	ReGc0Points uint    // number of points to use on each side of the 1/x singularity when calculating ReGc0
 	ReGc0dw     float64 // distance away from the singularity to step when calculating ReGc0
+	ImGc0Method string  // how to bin the delta functions of ImGc0 (default HistogramMethod)
+	Broadening  string  // kernel spreading each ImGc0 delta function over bins (default none; HistogramMethod only)
+	BroadeningWidth float64 // width (eta or sigma) of the Broadening kernel
+	AdaptiveTolerance float64 // if > 0, refine the mesh in the D1, mu and F0 sums to this tolerance
+	AdaptiveMaxDepth uint // maximum refinements of a mesh cell (default DefaultAdaptiveMaxDepth)
+	Symmetrize bool // sum D1, mu and F0 over the irreducible wedge of the mesh only? (not with AdaptiveTolerance)
 	InitD1,     // initial values for self-consistent parameters
 	InitMu,
-	InitF0 float64
+	InitF0,
+	InitLambda float64
+	CriticalBetaMin, // range of beta to scan when looking for the superconducting transition
+	CriticalBetaMax float64
 
 	// system constant physical parameters
-	Alpha int8 // either -1 (d-wave) or +1 (s-wave)
+	Alpha int8 // either -1 (d-wave) or +1 (s-wave); used by SinGapModel
+	GapModel string // name of pair GapFunction (default SinGapModel)
+	GapMix float64 // relative size of the s component for DPlusISGapModel
 	T,    // hopping energy for the physical electron
 	T0, // Overall energy scale (default = 1.0)
 	Tz, // z-direction hopping energy (|tz| < 0.3 or so)
 	Thp, // Diagonal (next-nearest-neighbor) hopping energy (similar range as tz)
+	Tp, // next-nearest-neighbor hopping for the physical electron
+	Tpp, // third-nearest-neighbor hopping for the physical electron
+	Tez, // interlayer (c-axis) hopping for the physical electron (3D forms only)
 	X, // Doping / holon excess (0 < x < ~0.2)
 	DeltaS, // spin gap
 	CS float64 // coefficient for k deviation in omega_q
 	Superconducting bool // are we in the superconducting phase?
+	Beta float64 // inverse temperature (used only by finite-temperature calculations)
+	FreeLambda bool // use SpinonLambda for Lambda() instead of sqrt(DeltaS^2 + CS^2)?
+	HoleModel, // name of holon Dispersion (default HolonModel)
+	ElectronModel string // name of electron Dispersion (default TightBindingModel)
+	HoleHoppings, // hopping tables used by TableModel
+	ElectronHoppings []Hopping
 
 	// self-consistently determined physical parameters
 	D1, // diagonal hopping parameter generated by two-hole process
 	Mu, // holon chemical potential
-	F0 float64 // superconducting order parameter
+	F0, // superconducting order parameter
+	SpinonLambda float64 // spinon chemical potential (only used if FreeLambda is set)
 
 	// cached value: must be reset with EpsilonMin() if D1 changes
 	EpsilonMin float64
+	// models found by ResolveModels(); each is used only while the name (and
+	// hopping table) it was resolved from is unchanged
+	holeDispersion, electronDispersion resolvedDispersion
+	gap resolvedGap
 }
 
 // The one-holon hopping energy Th is determined by Environment parameters
@@ -47,17 +73,48 @@ func (env *Environment) Th() float64 {
 
 // Spinon chemical potential
 func (env *Environment) Lambda() float64 {
+	if env.FreeLambda {
+		return env.SpinonLambda
+	}
 	return math.Sqrt(math.Pow(env.DeltaS, 2.0) + math.Pow(env.CS, 2.0))
 }
 
-// Set self-consistent parameters to the initial values as specified by the Environment
-func (env *Environment) Initialize() {
+// Set self-consistent parameters to the initial values as specified by the
+// Environment.  Returns an error if a model name is not recognized.
+func (env *Environment) Initialize() error {
+	if err := env.ResolveModels(); err != nil {
+		return err
+	}
 	// specified defaults
 	env.D1 = env.InitD1
 	env.Mu = env.InitMu
 	env.F0 = env.InitF0
+	env.SpinonLambda = env.InitLambda
 	// must be determined after system is otherwise initialized
 	env.EpsilonMin = EpsilonMin(*env)
+	return nil
+}
+
+// Look up the models named in env and keep them, so that the hot loops
+// don't repeat the lookup.  A kept model is ignored once its name or hopping
+// table changes; the lookup is then done on every call until ResolveModels
+// is called again.  Returns an error if a name is not recognized.
+func (env *Environment) ResolveModels() error {
+	hole, err := resolveDispersion(env.HoleModel, HolonModel, env.HoleHoppings)
+	if err != nil {
+		return err
+	}
+	electron, err := resolveDispersion(env.ElectronModel, TightBindingModel, env.ElectronHoppings)
+	if err != nil {
+		return err
+	}
+	gap, err := gapByName(env.GapModel)
+	if err != nil {
+		return err
+	}
+	env.holeDispersion, env.electronDispersion = hole, electron
+	env.gap = resolvedGap{env.GapModel, gap}
+	return nil
 }
 
 func (env *Environment) ZeroTempErrors() string {
@@ -123,13 +180,61 @@ func EnvironmentFromObject(jsonObject map[string]interface{}) (*Environment, err
 			field.Set(reflect.ValueOf(uint32(value.(float64))))
 		} else if fieldType == "int8" {
 			field.Set(reflect.ValueOf(int8(value.(float64))))
+		} else if field.Type() == reflect.TypeOf([]Hopping{}) {
+			hoppings, err := hoppingsFromObject(value)
+			if err != nil {
+				return nil, err
+			}
+			field.Set(reflect.ValueOf(hoppings))
 		} else {
 			field.Set(reflect.ValueOf(value))
 		}
 	}
+	if err := env.ResolveModels(); err != nil {
+		return nil, err
+	}
+	if err := checkImGc0Method(env.ImGc0Method); err != nil {
+		return nil, err
+	}
+	if _, err := kernelByName(env.Broadening, env.BroadeningWidth); err != nil {
+		return nil, err
+	}
+	if env.ImGc0Method == TriangleMethod && env.Broadening != "" {
+		return nil, errors.New("broadening is not supported by the triangle ImGc0 method")
+	}
+	if env.Symmetrize && env.AdaptiveTolerance > 0.0 {
+		return nil, errors.New("symmetrized sums are not supported with adaptive refinement")
+	}
 	return env, nil
 }
 
+// Convert a JSON list of {"X":_, "Y":_, "T":_} objects to Hoppings
+func hoppingsFromObject(value interface{}) ([]Hopping, error) {
+	// an empty table is written as null
+	if value == nil {
+		return nil, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, errors.New("hopping table must be a list")
+	}
+	hoppings := make([]Hopping, len(list))
+	for i, item := range list {
+		h, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("hopping table entry must be an object")
+		}
+		x, okX := h["X"].(float64)
+		y, okY := h["Y"].(float64)
+		t, okT := h["T"].(float64)
+		if !okX || !okY || !okT {
+			return nil, errors.New("hopping table entry must have numeric X, Y, and T")
+		}
+		hoppings[i] = Hopping{int(x), int(y), t}
+	}
+	return hoppings, nil
+}
+
 // Write the Environment to a JSON file at the given path
 func (env *Environment) WriteToFile(filePath string) error {
 	if err := WriteToJSONFile(env, filePath); err != nil {
@@ -23,3 +23,30 @@ func TestEnvironmentConvert(t *testing.T) {
 		t.Fatal("Environment does not match known value")
 	}
 }
+
+// Does an Environment written by String() load back unchanged, with and
+// without hopping tables?
+func TestEnvironmentRoundTrip(t *testing.T) {
+	env, err := EnvironmentFromFile("environment_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	withTable := *env
+	withTable.HoleModel = TableModel
+	withTable.HoleHoppings = []Hopping{{1, 0, 0.4}, {1, 1, -0.1}}
+	if err := withTable.ResolveModels(); err != nil {
+		t.Fatal(err)
+	}
+	for _, original := range []*Environment{env, &withTable} {
+		loaded, err := EnvironmentFromString(original.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(loaded, original) {
+			t.Fatalf("Environment changed on round trip: %s became %s", original.String(), loaded.String())
+		}
+	}
+}
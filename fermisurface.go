@@ -0,0 +1,267 @@
+package polecalc
+
+import "math"
+
+// Fermi surface given as the contours in k space where a pole of G crosses
+// omega = 0, ie where Re[1/G(k, 0)] changes sign through 0.  Re[1/G] also
+// changes sign through infinity at zeros of G (where ReGc0 = 0); these are
+// left out by also requiring the pole equation 1 - epsilon_k*ReGc0 to change
+// sign.
+type FermiSurfaceData struct {
+	// Polylines along the Fermi surface.  Every contour is closed: its last
+	// point is a copy of the first.  Contours which cross the zone boundary
+	// jump between k = pi and k = -pi.
+	Contours [][]Vector2
+	// Fraction of the Brillouin zone inside the Fermi surface (occupied
+	// states).  A region bounded by the contours is occupied if Re[1/G(k, 0)]
+	// > 0 next to its Fermi surface, so zeros of G inside it don't count.
+	LuttingerVolume float64
+}
+
+// Find the Fermi surface on a mesh with resolution points per side.
+func FermiSurface(env Environment, resolution uint32) (*FermiSurfaceData, error) {
+	greens := func(k Vector2) (float64, float64, error) {
+		ReGc0, ImGc0, err := zeroTempGc0(env, k, 0.0)
+		if err != nil {
+			return 0.0, 0.0, err
+		}
+		epsilon_k := ZeroTempElectronEnergy(env, k)
+		return ReGc0/(ReGc0*ReGc0+ImGc0*ImGc0) - epsilon_k, 1.0 - epsilon_k*ReGc0, nil
+	}
+	return fermiSurfaceFrom(resolution, greens)
+}
+
+// Fermi surface for greens, which gives Re[1/G(k, 0)] and the pole equation
+// at k.
+func fermiSurfaceFrom(resolution uint32, greens func(Vector2) (float64, float64, error)) (*FermiSurfaceData, error) {
+	N := uint64(resolution) * uint64(resolution)
+	values, poles := make([]float64, N), make([]float64, N)
+	for i := uint64(0); i < N; i++ {
+		var err error
+		values[i], poles[i], err = greens(SquareAt(i, resolution))
+		if err != nil {
+			return nil, err
+		}
+	}
+	contours, inside := meshContours(resolution, values, poles)
+	return &FermiSurfaceData{contours, inside}, nil
+}
+
+// Total length of the Fermi surface contours.
+func (fs *FermiSurfaceData) ArcLength() float64 {
+	length := 0.0
+	for _, contour := range fs.Contours {
+		for i := 1; i < len(contour); i++ {
+			length += minimumImage(contour[i].Sub(contour[i-1])).Norm()
+		}
+	}
+	return length
+}
+
+// Electrons per site implied by Luttinger's theorem (2 spins per k state).
+func (fs *FermiSurfaceData) LuttingerDensity() float64 {
+	return 2 * fs.LuttingerVolume
+}
+
+// Difference between the Luttinger density and the electron density 1 - x.
+func (fs *FermiSurfaceData) LuttingerError(env Environment) float64 {
+	return fs.LuttingerDensity() - (1 - env.X)
+}
+
+// Shift the components of v by multiples of 2pi into [-pi, pi).
+func minimumImage(v Vector2) Vector2 {
+	wrap := func(x float64) float64 {
+		return x - 2*math.Pi*math.Floor((x+math.Pi)/(2*math.Pi))
+	}
+	return Vector2{wrap(v.X), wrap(v.Y)}
+}
+
+// --- marching squares on the periodic square mesh ---
+
+// An edge of the mesh: from point (i, j) to (i+1, j), or to (i, j+1) if vertical.
+type meshEdge struct {
+	i, j     uint32
+	vertical bool
+}
+
+// Values of f at the points of the square mesh of L points per side, in the
+// order of SquareAt.
+func meshValues(L uint32, f Consumer) []float64 {
+	values := make([]float64, uint64(L)*uint64(L))
+	for i, _ := range values {
+		values[i] = f(SquareAt(uint64(i), L))
+	}
+	return values
+}
+
+// Find the contours where the mesh values cross 0 on the periodic mesh of L
+// points per side (with the same points as SquareAt), and the fraction of
+// the mesh inside them.  An edge is only crossed if poles also changes sign
+// along it; pass values as poles to use every sign change.  Each region
+// bounded by the contours is inside if values > 0 at the points next to its
+// contours (or, with no contours, if values > 0 at most of its points).
+func meshContours(L uint32, values, poles []float64) ([][]Vector2, float64) {
+	index := func(i, j uint32) uint64 {
+		return uint64(j%L)*uint64(L) + uint64(i%L)
+	}
+	at := func(i, j uint32) float64 {
+		return values[index(i, j)]
+	}
+	// is the edge from (i1, j1) to (i2, j2) crossed?
+	crosses := func(i1, j1, i2, j2 uint32) bool {
+		m, n := index(i1, j1), index(i2, j2)
+		return (values[m] > 0) != (values[n] > 0) && (poles[m] > 0) != (poles[n] > 0)
+	}
+	step := 2 * math.Pi / float64(L)
+	// the point where the values cross 0 along edge e
+	crossing := func(e meshEdge) Vector2 {
+		v0 := at(e.i, e.j)
+		x, y := -math.Pi+float64(e.i)*step, -math.Pi+float64(e.j)*step
+		if e.vertical {
+			v1 := at(e.i, e.j+1)
+			return Vector2{x, y + step*v0/(v0-v1)}
+		}
+		v1 := at(e.i+1, e.j)
+		return Vector2{x + step*v0/(v0-v1), y}
+	}
+	// segments[n] joins the crossings on two edges; edgeSegments gives the
+	// (at most two) segments touching each edge
+	segments := [][2]meshEdge{}
+	edgeSegments := make(map[meshEdge][]int)
+	addSegment := func(e1, e2 meshEdge) {
+		edgeSegments[e1] = append(edgeSegments[e1], len(segments))
+		edgeSegments[e2] = append(edgeSegments[e2], len(segments))
+		segments = append(segments, [2]meshEdge{e1, e2})
+	}
+	for j := uint32(0); j < L; j++ {
+		for i := uint32(0); i < L; i++ {
+			ip, jp := (i+1)%L, (j+1)%L
+			a := at(i, j) > 0
+			bottom, right := meshEdge{i, j, false}, meshEdge{ip, j, true}
+			top, left := meshEdge{i, jp, false}, meshEdge{i, j, true}
+			crossed := []meshEdge{}
+			for _, edge := range []struct {
+				e     meshEdge
+				cross bool
+			}{{bottom, crosses(i, j, i+1, j)}, {right, crosses(i+1, j, i+1, j+1)},
+				{top, crosses(i, j+1, i+1, j+1)}, {left, crosses(i, j, i, j+1)}} {
+				if edge.cross {
+					crossed = append(crossed, edge.e)
+				}
+			}
+			if len(crossed) == 2 {
+				addSegment(crossed[0], crossed[1])
+			} else if len(crossed) == 4 {
+				// saddle: use the value at the centre of the cell
+				centre := (at(i, j) + at(i+1, j) + at(i+1, j+1) + at(i, j+1)) / 4
+				if (centre > 0) == a {
+					// a and c connected through the centre
+					addSegment(bottom, right)
+					addSegment(top, left)
+				} else {
+					addSegment(left, bottom)
+					addSegment(right, top)
+				}
+			}
+		}
+	}
+	// join segments into closed contours
+	used := make([]bool, len(segments))
+	contours := [][]Vector2{}
+	for n, _ := range segments {
+		if used[n] {
+			continue
+		}
+		used[n] = true
+		start, current := segments[n][0], segments[n][1]
+		contour := []Vector2{crossing(start), crossing(current)}
+		for current != start {
+			next := -1
+			for _, m := range edgeSegments[current] {
+				if !used[m] {
+					next = m
+					break
+				}
+			}
+			if next == -1 {
+				// contour can't be continued; leave it open
+				break
+			}
+			used[next] = true
+			if segments[next][0] == current {
+				current = segments[next][1]
+			} else {
+				current = segments[next][0]
+			}
+			contour = append(contour, crossing(current))
+		}
+		contours = append(contours, contour)
+	}
+	return contours, insideFraction(L, values, crosses)
+}
+
+// Fraction of the mesh inside the contours found by meshContours.  The
+// regions are the sets of points joined by mesh edges which are not crossed.
+func insideFraction(L uint32, values []float64, crosses func(i1, j1, i2, j2 uint32) bool) float64 {
+	N := uint64(L) * uint64(L)
+	index := func(i, j uint32) uint64 {
+		return uint64(j)*uint64(L) + uint64(i)
+	}
+	// union-find over the mesh points
+	parent := make([]uint64, N)
+	for n := range parent {
+		parent[n] = uint64(n)
+	}
+	root := func(n uint64) uint64 {
+		for parent[n] != n {
+			parent[n] = parent[parent[n]]
+			n = parent[n]
+		}
+		return n
+	}
+	// neighbours of (i, j) in the +x and +y directions
+	neighbours := func(i, j uint32) [2][2]uint32 {
+		return [2][2]uint32{{(i + 1) % L, j}, {i, (j + 1) % L}}
+	}
+	for j := uint32(0); j < L; j++ {
+		for i := uint32(0); i < L; i++ {
+			for _, nb := range neighbours(i, j) {
+				if !crosses(i, j, nb[0], nb[1]) {
+					parent[root(index(i, j))] = root(index(nb[0], nb[1]))
+				}
+			}
+		}
+	}
+	// votes[r] counts the points of region r next to a contour, +1 where
+	// values > 0 and -1 elsewhere; majority[r] does the same for all its points
+	votes, majority := make(map[uint64]int), make(map[uint64]int)
+	vote := func(counts map[uint64]int, n uint64) {
+		if values[n] > 0 {
+			counts[root(n)]++
+		} else {
+			counts[root(n)]--
+		}
+	}
+	for j := uint32(0); j < L; j++ {
+		for i := uint32(0); i < L; i++ {
+			vote(majority, index(i, j))
+			for _, nb := range neighbours(i, j) {
+				if crosses(i, j, nb[0], nb[1]) {
+					vote(votes, index(i, j))
+					vote(votes, index(nb[0], nb[1]))
+				}
+			}
+		}
+	}
+	inside := 0
+	for n := uint64(0); n < N; n++ {
+		count, ok := votes[root(n)]
+		if !ok {
+			count = majority[root(n)]
+		}
+		if count > 0 {
+			inside++
+		}
+	}
+	return float64(inside) / float64(N)
+}
@@ -0,0 +1,118 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Does meshContours find a single closed circle of the right length and area
+// for f(k) = r^2 - |k|^2?
+func TestMeshContoursCircle(t *testing.T) {
+	r := 2.0
+	f := func(k Vector2) float64 {
+		return r*r - k.NormSquared()
+	}
+	values := meshValues(128, f)
+	contours, inside := meshContours(128, values, values)
+	if len(contours) != 1 {
+		t.Fatalf("expected 1 contour, got %d", len(contours))
+	}
+	contour := contours[0]
+	if !contour[0].Equals(contour[len(contour)-1]) {
+		t.Fatalf("contour is not closed")
+	}
+	fs := FermiSurfaceData{contours, inside}
+	if math.Abs(fs.ArcLength()-2*math.Pi*r) > 1e-2 {
+		t.Fatalf("incorrect arc length (got %f, expected %f)", fs.ArcLength(), 2*math.Pi*r)
+	}
+	area := math.Pi * r * r / (4 * math.Pi * math.Pi)
+	if math.Abs(inside-area) > 1e-2 {
+		t.Fatalf("incorrect enclosed fraction (got %f, expected %f)", inside, area)
+	}
+}
+
+// Do contours crossing the zone boundary close across it?
+func TestMeshContoursPeriodic(t *testing.T) {
+	f := func(k Vector2) float64 {
+		return math.Cos(k.X) + math.Cos(k.Y) + 0.5
+	}
+	values := meshValues(64, f)
+	contours, _ := meshContours(64, values, values)
+	if len(contours) != 1 {
+		t.Fatalf("expected 1 contour, got %d", len(contours))
+	}
+	contour := contours[0]
+	if !contour[0].Equals(contour[len(contour)-1]) {
+		t.Fatalf("contour is not closed")
+	}
+}
+
+// Free electrons with epsilon(k) = |k|^2 - kF^2 have 1/G(k, 0) = -epsilon(k),
+// so the Fermi surface is the circle |k| = kF, which holds 1 - x electrons
+// per site when 2 pi kF^2 / (2 pi)^2 = 1 - x.
+func TestFermiSurfaceFreeElectrons(t *testing.T) {
+	env := Environment{X: 0.1}
+	kF := math.Sqrt(2 * math.Pi * (1 - env.X))
+	greens := func(k Vector2) (float64, float64, error) {
+		return kF*kF - k.NormSquared(), kF*kF - k.NormSquared(), nil
+	}
+	fs, err := fermiSurfaceFrom(256, greens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs.Contours) != 1 {
+		t.Fatalf("expected 1 contour, got %d", len(fs.Contours))
+	}
+	if math.Abs(fs.ArcLength()-2*math.Pi*kF) > 1e-2 {
+		t.Fatalf("incorrect arc length (got %f, expected %f)", fs.ArcLength(), 2*math.Pi*kF)
+	}
+	if lutt := fs.LuttingerError(env); math.Abs(lutt) > 1e-2 {
+		t.Fatalf("Luttinger density %f does not match filling %f", fs.LuttingerDensity(), 1-env.X)
+	}
+	// raising kF^2 by 0.5 adds 2 pi 0.5 / (2 pi)^2 electrons per site
+	shifted := func(k Vector2) (float64, float64, error) {
+		return kF*kF + 0.5 - k.NormSquared(), kF*kF + 0.5 - k.NormSquared(), nil
+	}
+	fs, err = fermiSurfaceFrom(256, shifted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 0.5 / (2 * math.Pi); math.Abs(fs.LuttingerError(env)-expected) > 1e-2 {
+		t.Fatalf("incorrect Luttinger error %f (expected %f)", fs.LuttingerError(env), expected)
+	}
+}
+
+// With Gc0 = 2 - |k|^2 and epsilon_k = 1, G has a pole at |k| = 1 and a zero
+// at |k|^2 = 2.  Only the pole is on the Fermi surface, and the region
+// between them (where Re[1/G] > 0) is occupied together with everything
+// outside the zero.
+func TestFermiSurfaceIgnoresZeros(t *testing.T) {
+	greens := func(k Vector2) (float64, float64, error) {
+		gc0 := 2 - k.NormSquared()
+		return 1/gc0 - 1, 1 - gc0, nil
+	}
+	fs, err := fermiSurfaceFrom(256, greens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs.Contours) != 1 {
+		t.Fatalf("expected 1 contour, got %d", len(fs.Contours))
+	}
+	if math.Abs(fs.ArcLength()-2*math.Pi) > 1e-2 {
+		t.Fatalf("incorrect arc length (got %f, expected %f)", fs.ArcLength(), 2*math.Pi)
+	}
+	if expected := 1 - 1/(4*math.Pi); math.Abs(fs.LuttingerVolume-expected) > 1e-2 {
+		t.Fatalf("incorrect Luttinger volume %f (expected %f)", fs.LuttingerVolume, expected)
+	}
+}
+
+// Errors from Re[1/G] are passed on by the Fermi surface search.
+func TestFermiSurfaceError(t *testing.T) {
+	greens := func(k Vector2) (float64, float64, error) {
+		return 0.0, 0.0, ErrorSingularMatrix
+	}
+	if _, err := fermiSurfaceFrom(8, greens); !errors.Is(err, ErrorSingularMatrix) {
+		t.Fatalf("expected error to be passed on, got %v", err)
+	}
+}
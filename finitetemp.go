@@ -0,0 +1,153 @@
+package polecalc
+
+import "math"
+
+// Returns the system of equations needed to solve the system at inverse
+// temperature beta.
+func NewFiniteTempTypedSystem(beta float64, tolerances []float64) *TypedSystem[Environment] {
+	eqD1 := FiniteTempD1Equation{beta}
+	eqMu := FiniteTempMuEquation{beta}
+	eqF0 := FiniteTempF0Equation{beta}
+	equations := []TypedEquation[Environment]{eqD1, eqMu, eqF0}
+	system := &TypedSystem[Environment]{Equations: equations, Tolerances: tolerances}
+	return system
+}
+
+// NewFiniteTempTypedSystem with untyped arguments
+func NewFiniteTempSystem(beta float64, tolerances []float64) *SelfConsistentSystem {
+	return UntypedSystem(NewFiniteTempTypedSystem(beta, tolerances))
+}
+
+// Return env with the inverse temperature set to beta.
+func withBeta(env Environment, beta float64) Environment {
+	env.Beta = beta
+	return env
+}
+
+// --- D1 equation ---
+
+// D1 = -1/(2N) \sum_k (1 - xi(k)/E(k) * tanh(beta*E(k)/2)) * sin(kx) * sin(ky)
+func FiniteTempD1AbsError(env Environment) float64 {
+	worker := func(k Vector2) float64 {
+		sx, sy := math.Sin(k.X), math.Sin(k.Y)
+		E := ZeroTempPairEnergy(env, k)
+		return -0.5 * (1 - Xi(env, k)*tanhOverEnergy(env.Beta, E)) * sx * sy
+	}
+	return env.D1 - AverageK(env, worker)
+}
+
+type FiniteTempD1Equation struct {
+	Beta float64
+}
+
+func (eq FiniteTempD1Equation) AbsError(env Environment) float64 {
+	return FiniteTempD1AbsError(withBeta(env, eq.Beta))
+}
+
+func (eq FiniteTempD1Equation) SetArguments(D1 float64, env Environment) Environment {
+	env = withBeta(env, eq.Beta)
+	env.D1 = D1
+	// Epsilon depends on D1 so we may have changed the minimum
+	env.EpsilonMin = EpsilonMin(env)
+	return env
+}
+
+func (eq FiniteTempD1Equation) Value(env Environment) float64 {
+	return env.D1
+}
+
+func (eq FiniteTempD1Equation) Range(env Environment) (float64, float64, error) {
+	return 0.0, 1.0, nil
+}
+
+// --- mu equation ---
+
+// x = 1/(2N) \sum_k (1 - xi(k)/E(k) * tanh(beta*E(k)/2))
+func FiniteTempMuAbsError(env Environment) float64 {
+	worker := func(k Vector2) float64 {
+		E := ZeroTempPairEnergy(env, k)
+		return 0.5 * (1 - Xi(env, k)*tanhOverEnergy(env.Beta, E))
+	}
+	return env.X - AverageK(env, worker)
+}
+
+type FiniteTempMuEquation struct {
+	Beta float64
+}
+
+func (eq FiniteTempMuEquation) AbsError(env Environment) float64 {
+	return FiniteTempMuAbsError(withBeta(env, eq.Beta))
+}
+
+func (eq FiniteTempMuEquation) SetArguments(Mu float64, env Environment) Environment {
+	env = withBeta(env, eq.Beta)
+	env.Mu = Mu
+	return env
+}
+
+func (eq FiniteTempMuEquation) Value(env Environment) float64 {
+	return env.Mu
+}
+
+// Same range as ZeroTempMuEquation.
+func (eq FiniteTempMuEquation) Range(env Environment) (float64, float64, error) {
+	return -2 * env.T0, -MachEpsFloat64(), nil
+}
+
+// --- F0 equation ---
+
+// 1/(t0+tz) = 1/N \sum_k |g(k)|^2 * tanh(beta*E(k)/2) / E(k)
+func FiniteTempF0AbsError(env Environment) float64 {
+	worker := func(k Vector2) float64 {
+		E := ZeroTempPairEnergy(env, k)
+		return GapFormFactorSquared(env, k) * tanhOverEnergy(env.Beta, E)
+	}
+	return 1/(env.T0+env.Tz) - AverageK(env, worker)
+}
+
+type FiniteTempF0Equation struct {
+	Beta float64
+}
+
+func (eq FiniteTempF0Equation) AbsError(env Environment) float64 {
+	return FiniteTempF0AbsError(withBeta(env, eq.Beta))
+}
+
+func (eq FiniteTempF0Equation) SetArguments(F0 float64, env Environment) Environment {
+	env = withBeta(env, eq.Beta)
+	env.F0 = F0
+	return env
+}
+
+func (eq FiniteTempF0Equation) Value(env Environment) float64 {
+	return env.F0
+}
+
+func (eq FiniteTempF0Equation) Range(env Environment) (float64, float64, error) {
+	return 0.0, 1.0, nil
+}
+
+// --- distribution functions ---
+
+// Fermi distribution at inverse temperature beta.
+func FiniteTempFermi(beta, energy float64) float64 {
+	return 1.0 / (math.Exp(beta*energy) + 1.0)
+}
+
+// Bose distribution at inverse temperature beta.  Diverges at energy = 0.
+func FiniteTempBose(beta, energy float64) float64 {
+	return 1.0 / (math.Exp(beta*energy) - 1.0)
+}
+
+// tanh(beta*E/2) = 1 - 2f(E); reduces to 1 at T = 0 for E > 0.
+func pairTanh(beta, energy float64) float64 {
+	return 1.0 - 2.0*FiniteTempFermi(beta, energy)
+}
+
+// tanh(beta*E/2)/E, which goes to beta/2 (instead of 0/0) at E = 0.
+func tanhOverEnergy(beta, energy float64) float64 {
+	if energy == 0.0 {
+		return beta / 2.0
+	}
+	return pairTanh(beta, energy) / energy
+}
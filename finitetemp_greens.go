@@ -0,0 +1,73 @@
+package polecalc
+
+// Finite-temperature versions of the noninteracting electron Green's function.
+// These keep the occupation factors n_q + f(E_h) and n_q + 1 - f(E_h) at
+// inverse temperature env.Beta; the ZeroTemp functions are the beta -> infinity
+// limit.
+
+var finiteTempImGc0Cache = NewListCache()
+
+// Delta function terms of ImGc0 with Bose and Fermi functions at env.Beta.
+func finiteTempDeltaTermsGc0(env Environment, k Vector2, q Vector2) ([]float64, []float64) {
+	bose := func(energy float64) float64 {
+		return FiniteTempBose(env.Beta, energy)
+	}
+	fermi := func(energy float64) float64 {
+		return FiniteTempFermi(env.Beta, energy)
+	}
+	return thermalDeltaTermsGc0(env, k, q, bose, fermi)
+}
+
+// Same output as ZeroTempImGc0, at inverse temperature env.Beta.
+func FiniteTempImGc0(env Environment, k Vector2) ([]float64, []float64) {
+	deltaTerms := func(q Vector2) ([]float64, []float64) {
+		return finiteTempDeltaTermsGc0(env, k, q)
+	}
+	return binImGc0(env, k, deltaTerms)
+}
+
+func FiniteTempImGc0Point(env Environment, k Vector2, omega float64) (float64, error) {
+	imPart, err := getFromCacheImGc0(finiteTempImGc0Cache, FiniteTempImGc0, env, k)
+	if err != nil {
+		return 0.0, err
+	}
+	return splineImGc0Point(imPart, omega)
+}
+
+func FiniteTempReGc0(env Environment, k Vector2, omega float64) (float64, error) {
+	imPart, err := getFromCacheImGc0(finiteTempImGc0Cache, FiniteTempImGc0, env, k)
+	if err != nil {
+		return 0.0, err
+	}
+	return splineReGc0(env, imPart, omega)
+}
+
+// real part of the full Green's function at inverse temperature env.Beta
+func FiniteTempFullReGc(env Environment, k Vector2, omega float64) (float64, error) {
+	ReGc0, ImGc0, err := finiteTempGc0(env, k, omega)
+	if err != nil {
+		return 0.0, err
+	}
+	return fullReGcFromGc0(ZeroTempElectronEnergy(env, k), ReGc0, ImGc0), nil
+}
+
+// imaginary part of the full Green's function at inverse temperature env.Beta
+func FiniteTempFullImGc(env Environment, k Vector2, omega float64) (float64, error) {
+	ReGc0, ImGc0, err := finiteTempGc0(env, k, omega)
+	if err != nil {
+		return 0.0, err
+	}
+	return fullImGcFromGc0(ZeroTempElectronEnergy(env, k), ReGc0, ImGc0), nil
+}
+
+func finiteTempGc0(env Environment, k Vector2, omega float64) (float64, float64, error) {
+	ReGc0, err := FiniteTempReGc0(env, k, omega)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	ImGc0, err := FiniteTempImGc0Point(env, k, omega)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	return ReGc0, ImGc0, nil
+}
@@ -0,0 +1,65 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// Does the finite-temperature system reproduce the zero-temperature solution
+// at very large beta?
+func TestFiniteTempLowTemperatureLimit(t *testing.T) {
+	tolerances := []float64{1e-6, 1e-6, 1e-6}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	zeroSolution, err := NewZeroTempSystem(tolerances).Solve(*env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	finiteSolution, err := NewFiniteTempSystem(1e6, tolerances).Solve(*env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zeroEnv, finiteEnv := zeroSolution.(Environment), finiteSolution.(Environment)
+	if finiteEnv.Beta != 1e6 {
+		t.Fatalf("finite-temperature solution has wrong beta (%f)", finiteEnv.Beta)
+	}
+	if math.Abs(zeroEnv.D1-finiteEnv.D1) > 1e-6 || math.Abs(zeroEnv.Mu-finiteEnv.Mu) > 1e-6 || math.Abs(zeroEnv.F0-finiteEnv.F0) > 1e-6 {
+		t.Fatalf("finite-temperature solution does not match zero-temperature solution: got\n%s, expected\n%s", finiteEnv.String(), zeroEnv.String())
+	}
+}
+
+// Do the finite-temperature Gc0 functions agree with the zero-temperature
+// ones at very large beta?
+func TestFiniteTempGc0LowTemperatureLimit(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 16
+	env.ImGc0Bins = 128
+	env.Beta = 1e6
+	k := Vector2{0.25 * math.Pi, 0.5 * math.Pi}
+	zeroOmegas, zeroIm := ZeroTempImGc0(*env, k)
+	finiteOmegas, finiteIm := FiniteTempImGc0(*env, k)
+	for i, omega := range zeroOmegas {
+		if omega != finiteOmegas[i] || math.Abs(zeroIm[i]-finiteIm[i]) > 1e-12 {
+			t.Fatalf("ImGc0 mismatch at omega = %f: zero-temp %f, finite-temp %f", omega, zeroIm[i], finiteIm[i])
+		}
+	}
+	for _, omega := range []float64{-2.0, 0.5, 3.0} {
+		zeroRe, err := FullReGc(*env, k, omega)
+		if err != nil {
+			t.Fatal(err)
+		}
+		finiteRe, err := FiniteTempFullReGc(*env, k, omega)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(zeroRe-finiteRe) > 1e-9 {
+			t.Fatalf("ReGc mismatch at omega = %f: zero-temp %f, finite-temp %f", omega, zeroRe, finiteRe)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+)
+
+// k dependence g(k) of the holon pair gap Delta(k) = 4F0(T0 + Tz)g(k).
+// g may be complex (time-reversal-breaking gaps); FormFactor returns its real
+// and imaginary parts.
+type GapFunction interface {
+	FormFactor(env Environment, k Vector2) (float64, float64)
+}
+
+// Names used to select a GapFunction in Environment.GapModel.  An empty name
+// selects SinGapModel.
+const (
+	SinGapModel       = "sin"
+	ExtendedSGapModel = "extended-s"
+	DX2Y2GapModel     = "d-x2-y2"
+	DXYGapModel       = "d-xy"
+	DPlusISGapModel   = "d+is"
+)
+
+// sin(kx) + alpha*sin(ky): d-like for Alpha = -1, s-like for Alpha = +1
+type SinGap struct{}
+
+func (g SinGap) FormFactor(env Environment, k Vector2) (float64, float64) {
+	return math.Sin(k.X) + float64(env.Alpha)*math.Sin(k.Y), 0.0
+}
+
+// cos(kx) + cos(ky)
+type ExtendedSGap struct{}
+
+func (g ExtendedSGap) FormFactor(env Environment, k Vector2) (float64, float64) {
+	return math.Cos(k.X) + math.Cos(k.Y), 0.0
+}
+
+// cos(kx) - cos(ky)
+type DX2Y2Gap struct{}
+
+func (g DX2Y2Gap) FormFactor(env Environment, k Vector2) (float64, float64) {
+	return math.Cos(k.X) - math.Cos(k.Y), 0.0
+}
+
+// 2 sin(kx) sin(ky)
+type DXYGap struct{}
+
+func (g DXYGap) FormFactor(env Environment, k Vector2) (float64, float64) {
+	return 2 * math.Sin(k.X) * math.Sin(k.Y), 0.0
+}
+
+// (cos(kx) - cos(ky)) + i*GapMix: d_{x^2-y^2} plus an isotropic s component
+// of relative size GapMix, pi/2 out of phase.
+type DPlusISGap struct{}
+
+func (g DPlusISGap) FormFactor(env Environment, k Vector2) (float64, float64) {
+	return math.Cos(k.X) - math.Cos(k.Y), env.GapMix
+}
+
+// GapFunction selected by env.GapModel
+func (env *Environment) Gap() GapFunction {
+	if env.gap.model != nil && env.gap.name == env.GapModel {
+		return env.gap.model
+	}
+	g, err := gapByName(env.GapModel)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// |g(k)|^2; this is the form factor appearing in the F0 equation.
+func GapFormFactorSquared(env Environment, k Vector2) float64 {
+	re, im := env.Gap().FormFactor(env, k)
+	return re*re + im*im
+}
+
+// A GapFunction found by ResolveModels, with the name it was found from.
+type resolvedGap struct {
+	name  string
+	model GapFunction
+}
+
+func gapByName(name string) (GapFunction, error) {
+	switch name {
+	case "", SinGapModel:
+		return SinGap{}, nil
+	case ExtendedSGapModel:
+		return ExtendedSGap{}, nil
+	case DX2Y2GapModel:
+		return DX2Y2Gap{}, nil
+	case DXYGapModel:
+		return DXYGap{}, nil
+	case DPlusISGapModel:
+		return DPlusISGap{}, nil
+	}
+	return nil, errors.New("unknown gap model " + name)
+}
@@ -0,0 +1,56 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// The default gap should be the original sin(kx) + alpha*sin(ky) form.
+func TestDefaultGap(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	worker := func(k Vector2) float64 {
+		sx, sy := math.Sin(k.X), math.Sin(k.Y)
+		expected := 4 * env.F0 * (env.T0 + env.Tz) * (sx + float64(env.Alpha)*sy)
+		re, im := ZeroTempDelta(*env, k)
+		return math.Abs(re-expected) + math.Abs(im)
+	}
+	if diff := Maximum(env.GridLength, worker); diff > 1e-12 {
+		t.Fatalf("default gap changed by %e", diff)
+	}
+}
+
+// d+is with no s component is d_{x^2-y^2}; with an s component the F0
+// equation picks up |g|^2 = (cx - cy)^2 + GapMix^2.
+func TestDPlusISGap(t *testing.T) {
+	env, err := EnvironmentFromString(`{"GridLength":8, "InitD1":0.1,
+		"InitMu":-0.1, "InitF0":0.1, "T0":1.0, "Tz":0.1, "X":0.1,
+		"GapModel":"d-x2-y2"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	dEnv := *env
+	dEnv.GapModel = DPlusISGapModel
+	if d, dis := ZeroTempF0AbsError(*env), ZeroTempF0AbsError(dEnv); math.Abs(d-dis) > 1e-12 {
+		t.Fatalf("d+is with GapMix = 0 differs from d (%f, %f)", dis, d)
+	}
+	dEnv.GapMix = 0.5
+	worker := func(k Vector2) float64 {
+		d := math.Cos(k.X) - math.Cos(k.Y)
+		return math.Abs(GapFormFactorSquared(dEnv, k) - (d*d + 0.25))
+	}
+	if diff := Maximum(env.GridLength, worker); diff > 1e-12 {
+		t.Fatalf("d+is form factor incorrect by %e", diff)
+	}
+}
+
+func TestUnknownGap(t *testing.T) {
+	_, err := EnvironmentFromString(`{"GapModel":"p-wave"}`)
+	if err == nil {
+		t.Fatal("unknown gap model accepted")
+	}
+}
@@ -4,13 +4,15 @@ import matplotlib.pyplot as plt
 from matplotlib.ticker import FormatStrFormatter
 from matplotlib.font_manager import FontProperties
 from numpy import arange
+from numpy.ma import masked_invalid
 
 _GRAPH_DEFAULTS = {"xlabel":"$x$", "ylabel":"$y$", "num_ticks":5, 
     "axis_label_fontsize":"large", "tick_formatstr":"%.2f",
     "legend_fontsize":"large", "legend_loc":0, "legend_title":None, 
     "graph_filepath":None}
 
-_SERIES_DEFAULTS = {"label":None, "style":"k."}
+_SERIES_DEFAULTS = {"label":None, "style":"k.", "kind":"plot",
+    "marker_scale":"100"}
 
 def parse_file(file_path):
     '''Return the plot representation of the JSON file specified.'''
@@ -68,6 +70,25 @@ def make_graph(graph_data):
 
 def _graph_series(graph_data, series, fig, axes, bounds):
     # -- todo : set ticks --
+    if series["kind"] == "scatter":
+        # third column of data gives marker size and colour
+        weights = _wData(series)
+        scale = float(series["marker_scale"])
+        axes.scatter(_xData(series), _yData(series), 
+                     s=[scale * w for w in weights], c=weights, 
+                     label=series["label"])
+        return fig, axes, bounds
+    if series["kind"] == "heatmap":
+        # data points (x, y, value) on a grid; missing points are blank
+        xs = sorted(set(_xData(series)))
+        ys = sorted(set(_yData(series)))
+        grid = [[float("nan") for x in xs] for y in ys]
+        for x, y, w in series["data"]:
+            grid[ys.index(y)][xs.index(x)] = w
+        mesh = axes.pcolormesh(xs, ys, masked_invalid(grid), 
+                               shading="nearest")
+        fig.colorbar(mesh, ax=axes, label=series["label"])
+        return fig, axes, bounds
     axes.plot(_xData(series), _yData(series), series["style"], 
               label=series["label"])
     return fig, axes, bounds
@@ -78,6 +99,9 @@ def _xData(series):
 def _yData(series):
     return [point[1] for point in series["data"]]
 
+def _wData(series):
+    return [point[2] for point in series["data"]]
+
 def _save_figure(graph_data, fig):
     if graph_data["graph_filepath"] is None:
         return
@@ -0,0 +1,99 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+)
+
+// --- spinon chemical potential (lambda) equation ---
+// Solving either equation sets env.FreeLambda so that Lambda() returns the
+// solved value instead of sqrt(DeltaS^2 + CS^2), and omega_q is built from it
+// (see ZeroTempOmega).
+
+// Spinon number condition: each site holds 1 - x Schwinger bosons, so
+// 2 - x = 1/N \sum_q lambda/omega_q * (1 + 2n(omega_q))
+// with omega_q = sqrt(lambda^2 - CS^2*((sx + sy)^2/2 - 1)).
+func lambdaAbsError(env Environment, bose OccupationFunc) float64 {
+	worker := func(q Vector2) float64 {
+		omega_q := ZeroTempOmega(env, q)
+		return env.Lambda() / omega_q * (1 + 2*bose(omega_q))
+	}
+	return 2 - env.X - Average(env.GridLength, worker)
+}
+
+// At T = 0 the Bose factor n(omega_q) vanishes.
+func ZeroTempLambdaAbsError(env Environment) float64 {
+	bose := func(energy float64) float64 {
+		return 0.0
+	}
+	return lambdaAbsError(env, bose)
+}
+
+// Spinon number condition at inverse temperature env.Beta.
+func FiniteTempLambdaAbsError(env Environment) float64 {
+	bose := func(energy float64) float64 {
+		return FiniteTempBose(env.Beta, energy)
+	}
+	return lambdaAbsError(env, bose)
+}
+
+func setLambda(lambda float64, env Environment) Environment {
+	env.SpinonLambda = lambda
+	env.FreeLambda = true
+	return env
+}
+
+// omega_q is real for lambda >= CS, and the spinon sum diverges as lambda
+// approaches CS if the mesh holds a point with sx + sy = 2.  At T = 0,
+// lambda/omega_q <= lambda/sqrt(lambda^2 - CS^2), which is below 2 - x once
+// lambda > CS/sqrt(1 - 1/(2 - x)^2); the upper end leaves room for the Bose
+// factor at finite temperature.
+func lambdaRange(env Environment) (float64, float64, error) {
+	if env.CS <= 0.0 {
+		return 0.0, 0.0, errors.New("lambda equation needs CS > 0")
+	}
+	zeroTempMax := env.CS / math.Sqrt(1-1/math.Pow(2-env.X, 2.0))
+	return env.CS * (1 + LambdaRangeMargin), 4 * zeroTempMax, nil
+}
+
+// Relative distance above CS at which the search for lambda starts, keeping
+// omega_q away from 0.
+const LambdaRangeMargin = 1e-9
+
+type ZeroTempLambdaEquation struct{}
+
+func (eq ZeroTempLambdaEquation) AbsError(env Environment) float64 {
+	return ZeroTempLambdaAbsError(env)
+}
+
+func (eq ZeroTempLambdaEquation) SetArguments(lambda float64, env Environment) Environment {
+	return setLambda(lambda, env)
+}
+
+func (eq ZeroTempLambdaEquation) Value(env Environment) float64 {
+	return env.Lambda()
+}
+
+func (eq ZeroTempLambdaEquation) Range(env Environment) (float64, float64, error) {
+	return lambdaRange(env)
+}
+
+type FiniteTempLambdaEquation struct {
+	Beta float64
+}
+
+func (eq FiniteTempLambdaEquation) AbsError(env Environment) float64 {
+	return FiniteTempLambdaAbsError(withBeta(env, eq.Beta))
+}
+
+func (eq FiniteTempLambdaEquation) SetArguments(lambda float64, env Environment) Environment {
+	return setLambda(lambda, withBeta(env, eq.Beta))
+}
+
+func (eq FiniteTempLambdaEquation) Value(env Environment) float64 {
+	return env.Lambda()
+}
+
+func (eq FiniteTempLambdaEquation) Range(env Environment) (float64, float64, error) {
+	return lambdaRange(env)
+}
@@ -0,0 +1,53 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// Can lambda be solved for alongside D1, mu and F0, one equation at a time
+// and simultaneously, and does Lambda() then return the solved value?
+func TestZeroTempSystemWithLambda(t *testing.T) {
+	tolerances := []float64{1e-6, 1e-6, 1e-6}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the root lies just above CS, where the spinon sum is steep; start the
+	// simultaneous solve near it
+	env.DeltaS, env.CS = 1.0, 0.1
+	env.FreeLambda, env.InitLambda = true, 0.1001
+	env.Initialize()
+	for _, simultaneous := range []bool{false, true} {
+		system := NewZeroTempTypedSystem(tolerances)
+		system.AddEquation(ZeroTempLambdaEquation{}, 1e-9)
+		system.Simultaneous = simultaneous
+		solvedEnv, err := system.Solve(*env)
+		if err != nil {
+			t.Fatalf("%s (simultaneous = %t)", err, simultaneous)
+		}
+		if !solvedEnv.FreeLambda || solvedEnv.Lambda() != solvedEnv.SpinonLambda {
+			t.Fatalf("Lambda() does not use the solved value (simultaneous = %t)", simultaneous)
+		}
+		if err := ZeroTempLambdaAbsError(solvedEnv); math.Abs(err) > 1e-9 {
+			t.Fatalf("lambda equation not solved (error %e, simultaneous = %t)", err, simultaneous)
+		}
+	}
+}
+
+// The spinon sum uses omega_q built from lambda, so the constraint is not
+// linear in lambda.
+func TestLambdaConstraintUsesLambda(t *testing.T) {
+	env := Environment{GridLength: 8, X: 0.1, DeltaS: 1.0, CS: 0.1}
+	for _, lambda := range []float64{0.1001, 0.2, 0.5} {
+		lambdaEnv := setLambda(lambda, env)
+		worker := func(q Vector2) float64 {
+			s := math.Sin(q.X) + math.Sin(q.Y)
+			return lambda / math.Sqrt(lambda*lambda+env.CS*env.CS*(1-0.5*s*s))
+		}
+		expected := 2 - env.X - Average(env.GridLength, worker)
+		if err := ZeroTempLambdaAbsError(lambdaEnv); math.Abs(err-expected) > 1e-12 {
+			t.Fatalf("incorrect spinon constraint at lambda = %f (got %f, expected %f)", lambda, err, expected)
+		}
+	}
+}
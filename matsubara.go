@@ -0,0 +1,39 @@
+package polecalc
+
+import "math"
+
+// --- Gc0 on the imaginary frequency axis ---
+
+// Fermionic Matsubara frequency omega_n = (2n + 1) pi / beta
+func MatsubaraFrequency(n int, beta float64) float64 {
+	return float64(2*n+1) * math.Pi / beta
+}
+
+// Gc0(k, i omega_n) at inverse temperature beta, found by evaluating the
+// four-term sum over q directly.  Each term has the same weight and pole as
+// the corresponding delta function in FiniteTempImGc0, so no binning is
+// needed.
+func MatsubaraGc0(env Environment, k Vector2, n int, beta float64) complex128 {
+	env.Beta = beta
+	iOmega := complex(0, MatsubaraFrequency(n, beta))
+	worker := func(q Vector2) complex128 {
+		poles, coeffs := finiteTempDeltaTermsGc0(env, k, q)
+		sum := complex(0, 0)
+		for i, pole := range poles {
+			// coeffs include the factor -pi from ImGc0 = -pi * weight * delta
+			weight := -coeffs[i] / math.Pi
+			sum += complex(weight, 0) / (iOmega - complex(pole, 0))
+		}
+		return sum
+	}
+	return ComplexAverage(env.GridLength, worker)
+}
+
+// Gc0(k, i omega_n) for n = 0, 1, ..., numFreqs - 1.
+func MatsubaraGc0Range(env Environment, k Vector2, numFreqs int, beta float64) []complex128 {
+	values := make([]complex128, numFreqs)
+	for n := 0; n < numFreqs; n++ {
+		values[n] = MatsubaraGc0(env, k, n, beta)
+	}
+	return values
+}
@@ -0,0 +1,36 @@
+package polecalc
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// Does MatsubaraGc0 agree with the spectral representation
+// Gc0(i omega_n) = -1/pi \int ImGc0(omega) / (i omega_n - omega) d omega
+// built from the binned FiniteTempImGc0?
+func TestMatsubaraSpectralRepresentation(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 16
+	env.ImGc0Bins = 2048
+	beta := 2.0
+	env.Beta = beta
+	k := Vector2{0.25 * math.Pi, 0.5 * math.Pi}
+	omegas, imValues := FiniteTempImGc0(*env, k)
+	step := omegas[1] - omegas[0]
+	for _, n := range []int{0, 3} {
+		iOmega := complex(0, MatsubaraFrequency(n, beta))
+		expected := complex(0, 0)
+		for i, omega := range omegas {
+			// ImGc0 values are weights per bin; use the bin centre
+			expected += complex(-imValues[i]/math.Pi, 0) / (iOmega - complex(omega+step/2, 0))
+		}
+		G := MatsubaraGc0(*env, k, n, beta)
+		if cmplx.Abs(G-expected) > 1e-2*cmplx.Abs(expected) {
+			t.Fatalf("Matsubara Gc0 at n = %d incorrect (got %v, expected %v)", n, G, expected)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package polecalc
+
+import "math"
+
+// Return coordinate from the cubic mesh of length L corresponding to the
+// index i.  x varies fastest, then y, then z; each (x, y) layer is ordered as
+// in SquareAt.
+// i=0 corresponds to (-pi, -pi, -pi); i=L^3-1 is (pi-step, pi-step, pi-step)
+func CubeAt(i uint64, L uint32) Vector3 {
+	L64 := uint64(L)
+	if i >= L64*L64*L64 {
+		panic("invalid index for cubic mesh")
+	}
+	nz := i / (L64 * L64)
+	k := SquareAt(i-nz*L64*L64, L)
+	step := 2 * math.Pi / float64(L)
+	return Vector3{k.X, k.Y, -math.Pi + float64(nz)*step}
+}
+
+type Callback3D func(k Vector3) error
+
+// call callback on all points in the cubic mesh of length L
+func CallOnCube(L uint32, callback Callback3D) error {
+	L64 := uint64(L)
+	N := L64 * L64 * L64
+	for i := uint64(0); i < N; i++ {
+		err := callback(CubeAt(i, L))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call callback at numPoints values of kz from -pi to pi at fixed (kx, ky)
+func CallOnKzLine(kxy Vector2, numPoints uint, callback Callback3D) error {
+	for _, kz := range MakeRange(-math.Pi, math.Pi, numPoints) {
+		err := callback(Vector3{kxy.X, kxy.Y, kz})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// Does CubeAt produce the expected points?
+func TestCubeAtKnown(t *testing.T) {
+	L, L64 := uint32(8), uint64(8)
+	step := 2 * math.Pi / float64(L)
+	points := []Vector3{CubeAt(0, L), CubeAt(L64-1, L), CubeAt(L64*L64, L), CubeAt(L64*L64*L64-1, L)}
+	expected := []Vector3{Vector3{-math.Pi, -math.Pi, -math.Pi}, Vector3{math.Pi - step, -math.Pi, -math.Pi}, Vector3{-math.Pi, -math.Pi, -math.Pi + step}, Vector3{math.Pi - step, math.Pi - step, math.Pi - step}}
+	for i, p := range points {
+		e := expected[i]
+		if !FuzzyEqual(p.X, e.X) || !FuzzyEqual(p.Y, e.Y) || !FuzzyEqual(p.Z, e.Z) {
+			t.Fatalf("cube point %d is not as expected (got %v, expected %v)", i, p, e)
+		}
+	}
+}
+
+// Average of cos(kz)^2 over the cubic mesh should be 1/2, and the minimum of
+// cos(kx) + cos(kz) should be -2.
+func TestCubeAggregates(t *testing.T) {
+	avgWorker := func(k Vector3) float64 {
+		return math.Cos(k.Z) * math.Cos(k.Z)
+	}
+	if avg := Average3D(16, avgWorker); math.Abs(avg-0.5) > 1e-12 {
+		t.Fatalf("average of cos(kz)^2 incorrect (got %f)", avg)
+	}
+	minWorker := func(k Vector3) float64 {
+		return math.Cos(k.X) + math.Cos(k.Z)
+	}
+	if min := Minimum3D(16, minWorker); math.Abs(min+2) > 1e-12 {
+		t.Fatalf("minimum of cos(kx) + cos(kz) incorrect (got %f)", min)
+	}
+}
+
+// The cubic mesh aggregates should give the same result split over several
+// workers as serially.
+func TestParallelCubeListen(t *testing.T) {
+	defer func(workers uint16) { GridWorkers = workers }(GridWorkers)
+	worker := func(k Vector3) float64 {
+		return math.Exp(math.Cos(k.X)) * math.Sin(k.Y+0.3) * math.Cos(k.Z-0.1)
+	}
+	deltaTerms := func(k Vector3) ([]float64, []float64) {
+		return []float64{math.Cos(k.X) + math.Cos(k.Y) + math.Cos(k.Z)}, []float64{1.0}
+	}
+	run := func() []float64 {
+		_, binned := DeltaBin3D(20, deltaTerms, -3.0, 3.0, 24)
+		return append([]float64{Average3D(20, worker), Minimum3D(20, worker), Maximum3D(20, worker)}, binned...)
+	}
+	GridWorkers = 1
+	serial := run()
+	GridWorkers = 5
+	parallel := run()
+	for i := range serial {
+		if math.Abs(parallel[i]-serial[i]) > 1e-14 {
+			t.Fatalf("parallel result %d differs from serial (%e, %e)", i, parallel[i], serial[i])
+		}
+	}
+}
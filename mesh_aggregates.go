@@ -1,6 +1,9 @@
 package polecalc
 
-import "math"
+import (
+	"math"
+	"sync"
+)
 
 // A function which does calculations based on data passed in on cmesh and returns results through accum
 type Consumer func(point Vector2) float64
@@ -10,15 +13,26 @@ type GridListener interface {
 	initialize() GridListener
 	grab(point Vector2) GridListener
 	result() interface{}
+	// copy which shares no mutable state with the original
+	fork() GridListener
+	// combine with a listener of the same type which grabbed other points
+	merge(other GridListener) GridListener
+}
+
+// A GridListener which can also absorb points of the cubic mesh
+type CubeListener interface {
+	GridListener
+	grab3D(point Vector3) GridListener
 }
 
 // --- Accumulator ---
 // Collects values passed through grab() to find an average
 type Accumulator struct {
-	worker     Consumer // function to average
-	value      float64  // sum of points seen so far
-	compensate float64  // used in Kahan summation to correct floating-point error
-	points     uint64   // number of points seen
+	worker     Consumer   // function to average
+	worker3D   Consumer3D // function to average over the cubic mesh
+	value      float64    // sum of points seen so far
+	compensate float64    // used in Kahan summation to correct floating-point error
+	points     uint64     // number of points seen
 }
 
 func (accum Accumulator) initialize() GridListener {
@@ -31,7 +45,14 @@ func (accum Accumulator) initialize() GridListener {
 // Handle new data.
 // Use Kahan summation algorithm to reduce error: implementation cribbed from Wikipedia
 func (accum Accumulator) grab(point Vector2) GridListener {
-	newValue := accum.worker(point)
+	return accum.add(accum.worker(point))
+}
+
+func (accum Accumulator) grab3D(point Vector3) GridListener {
+	return accum.add(accum.worker3D(point))
+}
+
+func (accum Accumulator) add(newValue float64) GridListener {
 	accum.value, accum.compensate = KahanSum(newValue, accum.value, accum.compensate)
 	accum.points += 1
 	return accum
@@ -44,6 +65,23 @@ func KahanSum(extraValue, oldValue, compensate float64) (float64, float64) {
 	return newValue, newCompensate
 }
 
+// Add the Kahan sum (otherValue, otherCompensate) to (value, compensate).
+func kahanMerge(value, compensate, otherValue, otherCompensate float64) (float64, float64) {
+	value, compensate = KahanSum(otherValue, value, compensate)
+	return KahanSum(-otherCompensate, value, compensate)
+}
+
+func (accum Accumulator) fork() GridListener {
+	return accum
+}
+
+func (accum Accumulator) merge(other GridListener) GridListener {
+	o := other.(Accumulator)
+	accum.value, accum.compensate = kahanMerge(accum.value, accum.compensate, o.value, o.compensate)
+	accum.points += o.points
+	return accum
+}
+
 // Average of points passed in through grab()
 func (accum Accumulator) result() interface{} {
 	return accum.value / float64(accum.points)
@@ -56,10 +94,66 @@ func NewAccumulator(worker Consumer) *Accumulator {
 	return accum
 }
 
+// Create a new accumulator for the cubic mesh
+func NewAccumulator3D(worker Consumer3D) *Accumulator {
+	accum := new(Accumulator)
+	accum.worker3D = worker
+	return accum
+}
+
+// --- accumulator for complex values ---
+type ComplexConsumer func(point Vector2) complex128
+
+// Collects complex values passed through grab() to find an average
+type ComplexAccumulator struct {
+	worker         ComplexConsumer
+	re, im         float64 // sums of real and imaginary parts
+	compRe, compIm float64 // Kahan compensation for re and im
+	points         uint64
+}
+
+func (accum ComplexAccumulator) initialize() GridListener {
+	accum.re, accum.im = 0.0, 0.0
+	accum.compRe, accum.compIm = 0.0, 0.0
+	accum.points = 0
+	return accum
+}
+
+func (accum ComplexAccumulator) grab(point Vector2) GridListener {
+	newValue := accum.worker(point)
+	accum.re, accum.compRe = KahanSum(real(newValue), accum.re, accum.compRe)
+	accum.im, accum.compIm = KahanSum(imag(newValue), accum.im, accum.compIm)
+	accum.points += 1
+	return accum
+}
+
+func (accum ComplexAccumulator) result() interface{} {
+	return complex(accum.re, accum.im) / complex(float64(accum.points), 0)
+}
+
+func (accum ComplexAccumulator) fork() GridListener {
+	return accum
+}
+
+func (accum ComplexAccumulator) merge(other GridListener) GridListener {
+	o := other.(ComplexAccumulator)
+	accum.re, accum.compRe = kahanMerge(accum.re, accum.compRe, o.re, o.compRe)
+	accum.im, accum.compIm = kahanMerge(accum.im, accum.compIm, o.im, o.compIm)
+	accum.points += o.points
+	return accum
+}
+
+func NewComplexAccumulator(worker ComplexConsumer) *ComplexAccumulator {
+	accum := new(ComplexAccumulator)
+	accum.worker = worker
+	return accum
+}
+
 // --- accumulator for minima ---
 type MinimumData struct {
-	worker  Consumer // function to minimize
-	minimum float64  // minimum value seen so far
+	worker   Consumer   // function to minimize
+	worker3D Consumer3D // function to minimize over the cubic mesh
+	minimum  float64    // minimum value seen so far
 }
 
 func (minData MinimumData) initialize() GridListener {
@@ -68,7 +162,14 @@ func (minData MinimumData) initialize() GridListener {
 }
 
 func (minData MinimumData) grab(point Vector2) GridListener {
-	newValue := minData.worker(point)
+	return minData.add(minData.worker(point))
+}
+
+func (minData MinimumData) grab3D(point Vector3) GridListener {
+	return minData.add(minData.worker3D(point))
+}
+
+func (minData MinimumData) add(newValue float64) GridListener {
 	if newValue < minData.minimum {
 		minData.minimum = newValue
 	}
@@ -79,18 +180,34 @@ func (minData MinimumData) result() interface{} {
 	return minData.minimum
 }
 
+func (minData MinimumData) fork() GridListener {
+	return minData
+}
+
+func (minData MinimumData) merge(other GridListener) GridListener {
+	minData.minimum = math.Min(minData.minimum, other.(MinimumData).minimum)
+	return minData
+}
+
 func NewMinimumData(worker Consumer) *MinimumData {
 	minData := new(MinimumData)
 	minData.worker = worker
 	return minData
 }
 
+func NewMinimumData3D(worker Consumer3D) *MinimumData {
+	minData := new(MinimumData)
+	minData.worker3D = worker
+	return minData
+}
+
 // --- accumulator for maximua ---
 // it'd be nice to combine this with MaximumData but maybe would lose some
 // speed - most common (?) use case is minimizing epsilon after changing D1
 type MaximumData struct {
-	worker  Consumer
-	maximum float64
+	worker   Consumer
+	worker3D Consumer3D
+	maximum  float64
 }
 
 func (maxData MaximumData) initialize() GridListener {
@@ -99,7 +216,14 @@ func (maxData MaximumData) initialize() GridListener {
 }
 
 func (maxData MaximumData) grab(point Vector2) GridListener {
-	newValue := maxData.worker(point)
+	return maxData.add(maxData.worker(point))
+}
+
+func (maxData MaximumData) grab3D(point Vector3) GridListener {
+	return maxData.add(maxData.worker3D(point))
+}
+
+func (maxData MaximumData) add(newValue float64) GridListener {
 	if newValue > maxData.maximum {
 		maxData.maximum = newValue
 	}
@@ -110,12 +234,27 @@ func (maxData MaximumData) result() interface{} {
 	return maxData.maximum
 }
 
+func (maxData MaximumData) fork() GridListener {
+	return maxData
+}
+
+func (maxData MaximumData) merge(other GridListener) GridListener {
+	maxData.maximum = math.Max(maxData.maximum, other.(MaximumData).maximum)
+	return maxData
+}
+
 func NewMaximumData(worker Consumer) *MaximumData {
 	maxData := new(MaximumData)
 	maxData.worker = worker
 	return maxData
 }
 
+func NewMaximumData3D(worker Consumer3D) *MaximumData {
+	maxData := new(MaximumData)
+	maxData.worker3D = worker
+	return maxData
+}
+
 // --- accumulator for (discrete approximation) delta functions ---
 
 // returns pair of slices of bin variable values and their associciated 
@@ -125,11 +264,13 @@ type DeltaTermsFunc func(point Vector2) ([]float64, []float64)
 
 type DeltaBinner struct {
 	deltaTerms        DeltaTermsFunc
+	deltaTerms3D      DeltaTermsFunc3D // used on the cubic mesh
 	binStart, binStop float64
 	numBins           uint
 	bins              []float64 // value of the function at various omega values
 	compensates       []float64 // compensation values for Kahan summation
 	numPoints         uint64
+	kernel            BroadeningKernel // nil to put each term in a single bin
 }
 
 func (binner DeltaBinner) initialize() GridListener {
@@ -142,8 +283,19 @@ func (binner DeltaBinner) initialize() GridListener {
 }
 
 func (binner DeltaBinner) grab(point Vector2) GridListener {
-	omegas, coeffs := binner.deltaTerms(point)
+	return binner.add(binner.deltaTerms(point))
+}
+
+func (binner DeltaBinner) grab3D(point Vector3) GridListener {
+	return binner.add(binner.deltaTerms3D(point))
+}
+
+func (binner DeltaBinner) add(omegas, coeffs []float64) GridListener {
 	for i, omega := range omegas {
+		if binner.kernel != nil {
+			binner.spread(omega, coeffs[i])
+			continue
+		}
 		n := binner.BinVarToIndex(omega)
 		binner.bins[n], binner.compensates[n] = KahanSum(coeffs[i], binner.bins[n], binner.compensates[n])
 	}
@@ -151,6 +303,33 @@ func (binner DeltaBinner) grab(point Vector2) GridListener {
 	return binner
 }
 
+// Add coeff spread over the bins by the kernel centered at binVar.  The part
+// of the kernel inside the cutoff and the bin range is renormalized so that
+// the full coeff is kept.
+func (binner DeltaBinner) spread(binVar, coeff float64) {
+	cutoff := binner.kernel.Cutoff()
+	left := math.Max(binVar-cutoff, binner.binStart)
+	right := math.Min(binVar+cutoff, binner.binStop)
+	first, last := binner.BinVarToIndex(left), binner.BinVarToIndex(right)
+	if last >= int(binner.numBins) {
+		last = int(binner.numBins) - 1
+	}
+	lowCDF := binner.kernel.CDF(left - binVar)
+	total := binner.kernel.CDF(right-binVar) - lowCDF
+	if total <= 0.0 {
+		n := binner.BinVarToIndex(binVar)
+		binner.bins[n], binner.compensates[n] = KahanSum(coeff, binner.bins[n], binner.compensates[n])
+		return
+	}
+	previous := lowCDF
+	for n := first; n <= last; n++ {
+		edge := math.Min(binner.IndexToBinVar(n+1), right)
+		cdf := binner.kernel.CDF(edge - binVar)
+		binner.bins[n], binner.compensates[n] = KahanSum(coeff*(cdf-previous)/total, binner.bins[n], binner.compensates[n])
+		previous = cdf
+	}
+}
+
 func (binner DeltaBinner) result() interface{} {
 	result := make([]float64, binner.numBins)
 	for i, val := range binner.bins {
@@ -159,6 +338,21 @@ func (binner DeltaBinner) result() interface{} {
 	return result
 }
 
+func (binner DeltaBinner) fork() GridListener {
+	binner.bins = make([]float64, binner.numBins)
+	binner.compensates = make([]float64, binner.numBins)
+	return binner
+}
+
+func (binner DeltaBinner) merge(other GridListener) GridListener {
+	o := other.(DeltaBinner)
+	for i := range binner.bins {
+		binner.bins[i], binner.compensates[i] = kahanMerge(binner.bins[i], binner.compensates[i], o.bins[i], o.compensates[i])
+	}
+	binner.numPoints += o.numPoints
+	return binner
+}
+
 func (binner DeltaBinner) Step() float64 {
 	return math.Abs(binner.binStop-binner.binStart) / float64(binner.numBins)
 }
@@ -179,9 +373,19 @@ func (binner DeltaBinner) IndexToBinVar(index int) float64 {
 }
 
 func (binner DeltaBinner) BinVarValues() []float64 {
-	values := make([]float64, binner.numBins)
+	return BinVarValues(binner.binStart, binner.binStop, binner.numBins)
+}
+
+// Values of the bin variable at the bins of a DeltaBinner with the given
+// range, without building the binner.
+func BinVarValues(binStart, binStop float64, numBins uint) []float64 {
+	if binStart > binStop {
+		binStart, binStop = binStop, binStart
+	}
+	step := math.Abs(binStop-binStart) / float64(numBins)
+	values := make([]float64, numBins)
 	for i, _ := range values {
-		values[i] = binner.IndexToBinVar(i)
+		values[i] = binStart + step*float64(i)
 	}
 	return values
 }
@@ -191,31 +395,138 @@ func NewDeltaBinner(deltaTerms DeltaTermsFunc, binStart, binStop float64, numBin
 		binStart, binStop = binStop, binStart
 	}
 	bins, compensates := make([]float64, numBins), make([]float64, numBins)
-	binner := &DeltaBinner{deltaTerms, binStart, binStop, numBins, bins, compensates, 0}
+	binner := &DeltaBinner{deltaTerms, nil, binStart, binStop, numBins, bins, compensates, 0, nil}
+	return binner
+}
+
+// DeltaBinner which spreads each term over neighbouring bins with kernel.
+func NewBroadenedDeltaBinner(deltaTerms DeltaTermsFunc, binStart, binStop float64, numBins uint, kernel BroadeningKernel) *DeltaBinner {
+	binner := NewDeltaBinner(deltaTerms, binStart, binStop, numBins)
+	binner.kernel = kernel
 	return binner
 }
 
+// --- accumulator for several sets of delta functions at once ---
+// Lets a single pass over the grid fill one DeltaBinner per (for example) k
+// point.
+type MultiDeltaBinner struct {
+	binners []DeltaBinner
+}
+
+func (multi MultiDeltaBinner) initialize() GridListener {
+	for i, binner := range multi.binners {
+		multi.binners[i] = binner.initialize().(DeltaBinner)
+	}
+	return multi
+}
+
+func (multi MultiDeltaBinner) grab(point Vector2) GridListener {
+	for i, binner := range multi.binners {
+		multi.binners[i] = binner.grab(point).(DeltaBinner)
+	}
+	return multi
+}
+
+func (multi MultiDeltaBinner) result() interface{} {
+	result := make([][]float64, len(multi.binners))
+	for i, binner := range multi.binners {
+		result[i] = binner.result().([]float64)
+	}
+	return result
+}
+
+func (multi MultiDeltaBinner) fork() GridListener {
+	binners := make([]DeltaBinner, len(multi.binners))
+	for i, binner := range multi.binners {
+		binners[i] = binner.fork().(DeltaBinner)
+	}
+	return MultiDeltaBinner{binners}
+}
+
+func (multi MultiDeltaBinner) merge(other GridListener) GridListener {
+	o := other.(MultiDeltaBinner)
+	for i, binner := range multi.binners {
+		multi.binners[i] = binner.merge(o.binners[i]).(DeltaBinner)
+	}
+	return multi
+}
+
+func NewMultiDeltaBinner(binners []*DeltaBinner) *MultiDeltaBinner {
+	multi := &MultiDeltaBinner{make([]DeltaBinner, len(binners))}
+	for i, binner := range binners {
+		multi.binners[i] = *binner
+	}
+	return multi
+}
+
 // -- utility functions --
+
+// Number of goroutines used by DoGridListen.  With more than one, the
+// functions given to Average, Minimum, Maximum, DeltaBin etc. must be safe to
+// call concurrently.
+var GridWorkers uint16 = 1
+
+// Pass every point of the square mesh to listener.  The mesh is split into
+// GridWorkers contiguous chunks, each handled by its own fork of listener;
+// the chunks are merged in order, so the result depends only on the number of
+// workers, not on scheduling.
 func DoGridListen(pointsPerSide uint32, listener GridListener) interface{} {
-	listener = listener.initialize()
 	sqrtN := uint64(pointsPerSide)
-	N := sqrtN * sqrtN
-	for i := uint64(0); i < N; i++ {
-		listener = listener.grab(SquareAt(i, pointsPerSide))
+	grabAt := func(listener GridListener, i uint64) GridListener {
+		return listener.grab(SquareAt(i, pointsPerSide))
+	}
+	return listenInChunks(sqrtN*sqrtN, listener, grabAt).result()
+}
+
+// Pass N points to listener in GridWorkers chunks as in DoGridListen.
+// grabAt passes point i to the given listener.
+func listenInChunks(N uint64, listener GridListener, grabAt func(GridListener, uint64) GridListener) GridListener {
+	numWorkers := uint64(GridWorkers)
+	if numWorkers <= 1 || N < numWorkers {
+		return listenOnRange(listener.initialize(), 0, N, grabAt)
+	}
+	chunks := make([]GridListener, numWorkers)
+	var wait sync.WaitGroup
+	for w := uint64(0); w < numWorkers; w++ {
+		start, stop := w*N/numWorkers, (w+1)*N/numWorkers
+		chunks[w] = listener.fork().initialize()
+		wait.Add(1)
+		go func(w, start, stop uint64) {
+			defer wait.Done()
+			chunks[w] = listenOnRange(chunks[w], start, stop, grabAt)
+		}(w, start, stop)
+	}
+	wait.Wait()
+	result := chunks[0]
+	for _, chunk := range chunks[1:] {
+		result = result.merge(chunk)
+	}
+	return result
+}
+
+// Pass points with indices in [start, stop) to listener.
+func listenOnRange(listener GridListener, start, stop uint64, grabAt func(GridListener, uint64) GridListener) GridListener {
+	for i := start; i < stop; i++ {
+		listener = grabAt(listener, i)
 	}
-	return listener.result()
+	return listener
 }
 
 // Find the average over a square grid of the function given by worker.
-// Spawn number of goroutines given by numWorkers.
+// Uses GridWorkers goroutines (see DoGridListen).
 // pointsPerSide is uint32 so that accum.points will fit in a uint64.
-// numWorkers is uint16 to avoid spawning a ridiculous number of processes.
 // Consumer is defined in utility.go
 func Average(pointsPerSide uint32, worker Consumer) float64 {
 	accum := NewAccumulator(worker)
 	return DoGridListen(pointsPerSide, *accum).(float64)
 }
 
+// Average over a square grid of a complex-valued worker.
+func ComplexAverage(pointsPerSide uint32, worker ComplexConsumer) complex128 {
+	accum := NewComplexAccumulator(worker)
+	return DoGridListen(pointsPerSide, *accum).(complex128)
+}
+
 func Minimum(pointsPerSide uint32, worker Consumer) float64 {
 	minData := NewMinimumData(worker)
 	return DoGridListen(pointsPerSide, *minData).(float64)
@@ -231,3 +542,49 @@ func Maximum(pointsPerSide uint32, worker Consumer) float64 {
 func DeltaBin(pointsPerSide uint32, deltaTerms *DeltaBinner) []float64 {
 	return DoGridListen(pointsPerSide, deltaTerms).([]float64)
 }
+
+// Bin all the delta function sets in multi over the grid at once.
+// result[i] is the binned result for the i'th DeltaBinner given to multi.
+func MultiDeltaBin(pointsPerSide uint32, multi *MultiDeltaBinner) [][]float64 {
+	return DoGridListen(pointsPerSide, *multi).([][]float64)
+}
+
+// --- cubic mesh versions ---
+
+type Consumer3D func(point Vector3) float64
+type DeltaTermsFunc3D func(point Vector3) ([]float64, []float64)
+
+// Pass every point of the cubic mesh to listener, split over GridWorkers
+// goroutines as in DoGridListen.
+func DoCubeListen(pointsPerSide uint32, listener CubeListener) interface{} {
+	L := uint64(pointsPerSide)
+	grabAt := func(listener GridListener, i uint64) GridListener {
+		return listener.(CubeListener).grab3D(CubeAt(i, pointsPerSide))
+	}
+	return listenInChunks(L*L*L, listener, grabAt).result()
+}
+
+func Average3D(pointsPerSide uint32, worker Consumer3D) float64 {
+	accum := NewAccumulator3D(worker)
+	return DoCubeListen(pointsPerSide, *accum).(float64)
+}
+
+func Minimum3D(pointsPerSide uint32, worker Consumer3D) float64 {
+	minData := NewMinimumData3D(worker)
+	return DoCubeListen(pointsPerSide, *minData).(float64)
+}
+
+func Maximum3D(pointsPerSide uint32, worker Consumer3D) float64 {
+	maxData := NewMaximumData3D(worker)
+	return DoCubeListen(pointsPerSide, *maxData).(float64)
+}
+
+// Bin the delta functions given by deltaTerms over the cubic mesh into
+// numBins bins between binStart and binStop.  Returns the bin variable values
+// and the binned coefficients.
+func DeltaBin3D(pointsPerSide uint32, deltaTerms DeltaTermsFunc3D, binStart, binStop float64, numBins uint) ([]float64, []float64) {
+	binner := NewDeltaBinner(nil, binStart, binStop, numBins)
+	binner.deltaTerms3D = deltaTerms
+	result := DoCubeListen(pointsPerSide, *binner).([]float64)
+	return binner.BinVarValues(), result
+}
@@ -39,3 +39,31 @@ func TestTwoDeltas(t *testing.T) {
 		}
 	}
 }
+
+// Parallel aggregation should agree with the serial result and be exactly
+// reproducible for a fixed number of workers.
+func TestParallelGridListen(t *testing.T) {
+	defer func(workers uint16) { GridWorkers = workers }(GridWorkers)
+	worker := func(k Vector2) float64 {
+		return math.Exp(math.Cos(k.X)) * math.Sin(k.Y+0.3)
+	}
+	deltaTerms := func(q Vector2) ([]float64, []float64) {
+		return []float64{math.Cos(q.X) + math.Cos(q.Y)}, []float64{1.0}
+	}
+	run := func() []float64 {
+		binned := DeltaBin(100, NewDeltaBinner(deltaTerms, -2.0, 2.0, 32))
+		return append([]float64{Average(100, worker), Minimum(100, worker), Maximum(100, worker)}, binned...)
+	}
+	GridWorkers = 1
+	serial := run()
+	GridWorkers = 7
+	parallel, again := run(), run()
+	for i := range serial {
+		if math.Abs(parallel[i]-serial[i]) > 1e-14 {
+			t.Fatalf("parallel result %d differs from serial (%e, %e)", i, parallel[i], serial[i])
+		}
+		if parallel[i] != again[i] {
+			t.Fatalf("parallel result %d not reproducible (%e, %e)", i, parallel[i], again[i])
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Pade analytic continuation from the imaginary axis to the real axis using
+// the continued fraction recursion of Vidberg and Serene
+// (J. Low Temp. Phys. 29, 179 (1977)).
+package polecalc
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// Bits of mantissa used for the Pade recursion.  The recursion loses
+// precision quickly, so float64 is not enough for more than ~20 points.
+const PadePrecision uint = 256
+
+// N-point Pade approximant C_N(z) = A_N(z)/B_N(z), stored as the continued
+// fraction coefficients a_p and the input points z_p.
+type PadeApproximant struct {
+	zs     []bigComplex
+	coeffs []bigComplex
+}
+
+// Build the approximant which takes the value us[i] at zs[i].
+// Returns an error if the recursion breaks down (a zero denominator) or a
+// coefficient can't be represented as a float64.
+func NewPadeApproximant(zs, us []complex128) (*PadeApproximant, error) {
+	if len(zs) != len(us) {
+		return nil, errors.New("input slices must be the same length")
+	}
+	if len(zs) == 0 {
+		return nil, errors.New("need at least one point for Pade approximant")
+	}
+	N := len(zs)
+	bigZs := make([]bigComplex, N)
+	// g[i] holds g_p(z_i) for the current p
+	g := make([]bigComplex, N)
+	for i, _ := range zs {
+		bigZs[i] = newBigComplex(zs[i])
+		g[i] = newBigComplex(us[i])
+	}
+	coeffs := make([]bigComplex, N)
+	coeffs[0] = g[0]
+	for p := 1; p < N; p++ {
+		// g_p(z) = (g_{p-1}(z_{p-1}) - g_{p-1}(z)) / ((z - z_{p-1}) g_{p-1}(z))
+		prev := g[p-1]
+		for i := p; i < N; i++ {
+			denom := bigZs[i].sub(bigZs[p-1]).mul(g[i])
+			if denom.isZero() {
+				return nil, errors.New("Pade recursion breakdown: zero denominator")
+			}
+			g[i] = prev.sub(g[i]).quo(denom)
+		}
+		coeffs[p] = g[p]
+		if c := coeffs[p].complex128(); cmplx.IsInf(c) || cmplx.IsNaN(c) {
+			return nil, errors.New("Pade coefficient outside float64 range")
+		}
+	}
+	return &PadeApproximant{bigZs, coeffs}, nil
+}
+
+// Value of the approximant at z.
+// A_{n+1} = A_n + (z - z_n) a_{n+1} A_{n-1}; likewise for B.
+func (pade *PadeApproximant) At(z complex128) (complex128, error) {
+	bigZ := newBigComplex(z)
+	Aprev, A := newBigComplex(0), pade.coeffs[0]
+	Bprev, B := newBigComplex(1), newBigComplex(1)
+	for n := 0; n < len(pade.coeffs)-1; n++ {
+		factor := bigZ.sub(pade.zs[n]).mul(pade.coeffs[n+1])
+		A, Aprev = A.add(factor.mul(Aprev)), A
+		B, Bprev = B.add(factor.mul(Bprev)), B
+	}
+	if B.isZero() {
+		return 0, errors.New("Pade approximant has a pole at the requested point")
+	}
+	value := A.quo(B).complex128()
+	if cmplx.IsInf(value) || cmplx.IsNaN(value) {
+		return 0, errors.New("Pade approximant value outside float64 range")
+	}
+	return value, nil
+}
+
+// Continue Gc0(k, i omega_n), n = 0, ..., numFreqs - 1 at inverse temperature
+// env.Beta to Gc0(k, omega + i eta) at each of omegas.
+func PadeGc0(env Environment, k Vector2, numFreqs int, eta float64, omegas []float64) ([]complex128, error) {
+	zs := make([]complex128, numFreqs)
+	for n, _ := range zs {
+		zs[n] = complex(0, MatsubaraFrequency(n, env.Beta))
+	}
+	us := MatsubaraGc0Range(env, k, numFreqs, env.Beta)
+	pade, err := NewPadeApproximant(zs, us)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]complex128, len(omegas))
+	for i, omega := range omegas {
+		values[i], err = pade.At(complex(omega, eta))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// Pointwise difference between the Pade continuation of Gc0 and the result
+// of FiniteTempReGc0 and FiniteTempImGc0Point (ImGc0 binned, ReGc0 from the
+// Kramers-Kronig relation).  The binned ImGc0 is a weight per bin, and ReGc0
+// inherits that scale, so both are divided by the bin width before comparing.
+func PadeKramersKronigDifference(env Environment, k Vector2, numFreqs int, eta float64, omegas []float64) ([]complex128, error) {
+	pade, err := PadeGc0(env, k, numFreqs, eta, omegas)
+	if err != nil {
+		return nil, err
+	}
+	imOmegas, _ := FiniteTempImGc0(env, k)
+	binWidth := imOmegas[1] - imOmegas[0]
+	diffs := make([]complex128, len(omegas))
+	for i, omega := range omegas {
+		re, err := FiniteTempReGc0(env, k, omega)
+		if err != nil {
+			return nil, err
+		}
+		im, err := FiniteTempImGc0Point(env, k, omega)
+		if err != nil {
+			return nil, err
+		}
+		diffs[i] = pade[i] - complex(re/binWidth, im/binWidth)
+	}
+	return diffs, nil
+}
+
+// --- arbitrary-precision complex arithmetic for the Pade recursion ---
+
+type bigComplex struct {
+	re, im *big.Float
+}
+
+func newBigFloat(x float64) *big.Float {
+	return new(big.Float).SetPrec(PadePrecision).SetFloat64(x)
+}
+
+func newBigComplex(z complex128) bigComplex {
+	return bigComplex{newBigFloat(real(z)), newBigFloat(imag(z))}
+}
+
+func (z bigComplex) add(w bigComplex) bigComplex {
+	re := newBigFloat(0).Add(z.re, w.re)
+	im := newBigFloat(0).Add(z.im, w.im)
+	return bigComplex{re, im}
+}
+
+func (z bigComplex) sub(w bigComplex) bigComplex {
+	re := newBigFloat(0).Sub(z.re, w.re)
+	im := newBigFloat(0).Sub(z.im, w.im)
+	return bigComplex{re, im}
+}
+
+func (z bigComplex) mul(w bigComplex) bigComplex {
+	re := newBigFloat(0).Sub(newBigFloat(0).Mul(z.re, w.re), newBigFloat(0).Mul(z.im, w.im))
+	im := newBigFloat(0).Add(newBigFloat(0).Mul(z.re, w.im), newBigFloat(0).Mul(z.im, w.re))
+	return bigComplex{re, im}
+}
+
+// z / w; w must not be zero.
+func (z bigComplex) quo(w bigComplex) bigComplex {
+	norm := newBigFloat(0).Add(newBigFloat(0).Mul(w.re, w.re), newBigFloat(0).Mul(w.im, w.im))
+	conj := bigComplex{w.re, newBigFloat(0).Neg(w.im)}
+	numer := z.mul(conj)
+	re := newBigFloat(0).Quo(numer.re, norm)
+	im := newBigFloat(0).Quo(numer.im, norm)
+	return bigComplex{re, im}
+}
+
+func (z bigComplex) isZero() bool {
+	return z.re.Sign() == 0 && z.im.Sign() == 0
+}
+
+// Nearest complex128; components too large for float64 become +-Inf.
+func (z bigComplex) complex128() complex128 {
+	re, _ := z.re.Float64()
+	im, _ := z.im.Float64()
+	if math.IsNaN(re) || math.IsNaN(im) {
+		return cmplx.NaN()
+	}
+	return complex(re, im)
+}
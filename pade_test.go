@@ -0,0 +1,35 @@
+package polecalc
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+// Does the Pade approximant exactly continue a rational function from the
+// imaginary axis to just above the real axis?
+func TestPadeRational(t *testing.T) {
+	f := func(z complex128) complex128 {
+		return 1/(z-1) + 0.5/(z+2)
+	}
+	beta := 10.0
+	numFreqs := 16
+	zs, us := make([]complex128, numFreqs), make([]complex128, numFreqs)
+	for n, _ := range zs {
+		zs[n] = complex(0, MatsubaraFrequency(n, beta))
+		us[n] = f(zs[n])
+	}
+	pade, err := NewPadeApproximant(zs, us)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, omega := range MakeRange(-3.0, 3.0, 13) {
+		z := complex(omega, 0.05)
+		val, err := pade.At(z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cmplx.Abs(val-f(z)) > 1e-9 {
+			t.Fatalf("Pade continuation at %v incorrect (got %v, expected %v)", z, val, f(z))
+		}
+	}
+}
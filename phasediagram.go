@@ -0,0 +1,147 @@
+package polecalc
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// Solution of the self-consistent system at one (x, beta) point.
+type PhasePoint struct {
+	X, Beta    float64 // Beta = 0 means T = 0
+	D1, Mu, F0 float64
+	Converged  bool
+	Err        error // reason the solution failed (nil if Converged)
+}
+
+// Self-consistent solutions on the grid Xs × Betas.  Points[i][j] is at
+// Betas[i] and Xs[j].
+type PhaseDiagram struct {
+	Xs, Betas []float64
+	Points    [][]PhasePoint
+}
+
+// Solve the self-consistent system at every combination of doping in xs and
+// inverse temperature in betas.  If betas is empty, solve at T = 0 instead.
+// Each point is seeded from the nearest (in grid index) point already
+// solved; if that fails it is retried from env's Init values.  Failures are
+// recorded in the PhasePoint rather than stopping the sweep.
+func SolvePhaseDiagram(env Environment, xs, betas []float64, tolerances []float64) *PhaseDiagram {
+	zeroTemp := len(betas) == 0
+	if zeroTemp {
+		betas = []float64{0.0}
+	}
+	pd := &PhaseDiagram{xs, betas, make([][]PhasePoint, len(betas))}
+	solved := make([][]*Environment, len(betas))
+	// env initialized from its Init values; set up only when first needed
+	var fresh *Environment
+	for i, beta := range betas {
+		pd.Points[i] = make([]PhasePoint, len(xs))
+		solved[i] = make([]*Environment, len(xs))
+		var system *TypedSystem[Environment]
+		if zeroTemp {
+			system = NewZeroTempTypedSystem(tolerances)
+		} else {
+			system = NewFiniteTempTypedSystem(beta, tolerances)
+		}
+		for j, x := range xs {
+			seed := nearestSolved(solved, i, j)
+			var solution Environment
+			var err error
+			if seed != nil {
+				solution, err = solvePhasePoint(system, *seed, x, beta)
+			}
+			if seed == nil || err != nil {
+				if fresh == nil {
+					initial := env
+					if err = initial.Initialize(); err == nil {
+						fresh = &initial
+					}
+				}
+				if fresh != nil {
+					solution, err = solvePhasePoint(system, *fresh, x, beta)
+				}
+			}
+			point := PhasePoint{X: x, Beta: beta, Converged: err == nil, Err: err}
+			if err == nil {
+				point.D1, point.Mu, point.F0 = solution.D1, solution.Mu, solution.F0
+				solved[i][j] = &solution
+			}
+			pd.Points[i][j] = point
+		}
+	}
+	return pd
+}
+
+// Solve system starting from env with doping x and inverse temperature beta.
+func solvePhasePoint(system *TypedSystem[Environment], env Environment, x, beta float64) (Environment, error) {
+	env.X = x
+	env.Beta = beta
+	// Th depends on x so the minimum of Epsilon may have moved
+	env.EpsilonMin = EpsilonMin(env)
+	return system.Solve(env)
+}
+
+// Return the solved Environment closest to (i, j), or nil if there is none.
+func nearestSolved(solved [][]*Environment, i, j int) *Environment {
+	var nearest *Environment
+	bestDistance := math.Inf(1)
+	for a, row := range solved {
+		for b, env := range row {
+			if env == nil {
+				continue
+			}
+			distance := math.Hypot(float64(a-i), float64(b-j))
+			if distance < bestDistance {
+				nearest, bestDistance = env, distance
+			}
+		}
+	}
+	return nearest
+}
+
+// Number of points where the solution failed.
+func (pd *PhaseDiagram) Failures() int {
+	count := 0
+	for _, row := range pd.Points {
+		for _, point := range row {
+			if !point.Converged {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Whitespace-separated table with one line per point.
+func (pd *PhaseDiagram) Table() string {
+	var buffer bytes.Buffer
+	buffer.WriteString("# x\tbeta\tD1\tmu\tF0\tconverged\n")
+	for _, row := range pd.Points {
+		for _, p := range row {
+			fmt.Fprintf(&buffer, "%f\t%f\t%f\t%f\t%f\t%t\n", p.X, p.Beta, p.D1, p.Mu, p.F0, p.Converged)
+		}
+	}
+	return buffer.String()
+}
+
+// Heatmap of F0 over (x, T).  Points which did not converge are left out.
+func (pd *PhaseDiagram) Graph() *Graph {
+	data := make([][]float64, 0)
+	for _, row := range pd.Points {
+		for _, p := range row {
+			if !p.Converged {
+				continue
+			}
+			temperature := 0.0
+			if p.Beta != 0.0 {
+				temperature = 1.0 / p.Beta
+			}
+			data = append(data, []float64{p.X, temperature, p.F0})
+		}
+	}
+	graph := NewGraph()
+	graph.SetGraphParameters(map[string]interface{}{"xlabel": "$x$", "ylabel": "$T$"})
+	graph.AddSeries(map[string]string{"kind": "heatmap", "label": "$F_0$"}, data)
+	return graph
+}
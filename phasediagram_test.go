@@ -0,0 +1,40 @@
+package polecalc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// A small T = 0 doping sweep should solve every point in the range where mu < 0
+// has a solution, each to the requested tolerance, and record the failure
+// outside that range.
+func TestZeroTempPhaseDiagram(t *testing.T) {
+	tolerances := []float64{1e-6, 1e-6, 1e-6}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xs := []float64{0.06, 0.08, 0.1, 0.2}
+	pd := SolvePhaseDiagram(*env, xs, nil, tolerances)
+	if last := pd.Points[0][len(xs)-1]; pd.Failures() != 1 || last.Converged || last.Err == nil {
+		t.Fatalf("phase diagram failures not as expected:\n%s", pd.Table())
+	}
+	for j, p := range pd.Points[0][:len(xs)-1] {
+		if p.X != xs[j] {
+			t.Fatalf("point %d at wrong doping %f", j, p.X)
+		}
+		solved := *env
+		solved.X, solved.D1, solved.Mu, solved.F0 = p.X, p.D1, p.Mu, p.F0
+		solved.EpsilonMin = EpsilonMin(solved)
+		if !NewZeroTempSystem(tolerances).IsSolved(solved) {
+			t.Fatalf("point at x = %f is not a solution (%s)", p.X, solved.ZeroTempErrors())
+		}
+	}
+	if lines := strings.Count(pd.Table(), "\n"); lines != len(xs)+1 {
+		t.Fatalf("table has %d lines; expected %d", lines, len(xs)+1)
+	}
+	if _, err := json.Marshal(pd.Graph()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,261 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+)
+
+// If a RootFinder takes more iterations than this, it gives up.
+const RootFinderMaxIterations = 200
+
+// Finds a root of f given a bracket [left, right] (f(left) and f(right) of
+// opposite sign, or one of them 0).  Iteration stops once the root is known
+// to within max(absTol, relTol*|root|); with both 0 it continues to full
+// precision (relative, or absolute for |root| < 1).
+type RootFinder interface {
+	FindRoot(f Func1D, left, right, absTol, relTol float64) (float64, error)
+}
+
+// Errors returned by the RootFinders (test with errors.Is).
+var ErrorNotBracketed = errors.New("arguments do not bracket a root")
+var ErrorRootIterations = errors.New("root finder exceeded RootFinderMaxIterations")
+
+// Tolerance on x near x for the given absolute and relative tolerances,
+// never below a few ulps of max(|x|, 1), so that a root at 0 can be reached.
+func rootTolerance(x, absTol, relTol float64) float64 {
+	return math.Max(math.Max(absTol, relTol*math.Abs(x)), 4*MachEpsFloat64()*math.Max(math.Abs(x), 1))
+}
+
+// BisectionFullPrecision.  Ignores tolerances.
+type BisectionFinder struct{}
+
+func (finder BisectionFinder) FindRoot(f Func1D, left, right, absTol, relTol float64) (float64, error) {
+	return BisectionFullPrecision(f, left, right)
+}
+
+// Brent's method: inverse quadratic interpolation and secant steps,
+// falling back to bisection when they don't make enough progress.
+// Follows Brent's zeroin.
+type BrentFinder struct{}
+
+func (finder BrentFinder) FindRoot(f Func1D, left, right, absTol, relTol float64) (float64, error) {
+	a, b := left, right
+	fa, fb := f(a), f(b)
+	if fa == 0 {
+		return a, nil
+	}
+	if fb == 0 {
+		return b, nil
+	}
+	if (fa > 0) == (fb > 0) {
+		return 0.0, ErrorNotBracketed
+	}
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for iter := 0; iter < RootFinderMaxIterations; iter++ {
+		if (fb > 0) == (fc > 0) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+		tol := 0.5 * rootTolerance(b, absTol, relTol)
+		m := 0.5 * (c - b)
+		if math.Abs(m) <= tol || fb == 0 {
+			return b, nil
+		}
+		if math.Abs(e) >= tol && math.Abs(fa) > math.Abs(fb) {
+			// try interpolation
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				// secant
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				// inverse quadratic
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*m*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2*p < math.Min(3*m*q-math.Abs(tol*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d, e = m, m
+			}
+		} else {
+			d, e = m, m
+		}
+		a, fa = b, fb
+		if math.Abs(d) > tol {
+			b += d
+		} else {
+			b += math.Copysign(tol, m)
+		}
+		fb = f(b)
+	}
+	return b, ErrorRootIterations
+}
+
+// Ridders' method: exponential fit through the bracket ends and midpoint.
+type RiddersFinder struct{}
+
+func (finder RiddersFinder) FindRoot(f Func1D, left, right, absTol, relTol float64) (float64, error) {
+	fl, fr := f(left), f(right)
+	if fl == 0 {
+		return left, nil
+	}
+	if fr == 0 {
+		return right, nil
+	}
+	if (fl > 0) == (fr > 0) {
+		return 0.0, ErrorNotBracketed
+	}
+	x := left
+	for iter := 0; iter < RootFinderMaxIterations; iter++ {
+		mid := 0.5 * (left + right)
+		fm := f(mid)
+		s := math.Sqrt(fm*fm - fl*fr)
+		if s == 0 {
+			return mid, nil
+		}
+		sign := 1.0
+		if fl < fr {
+			sign = -1.0
+		}
+		xNew := mid + (mid-left)*sign*fm/s
+		if iter > 0 && math.Abs(xNew-x) <= rootTolerance(xNew, absTol, relTol) {
+			return xNew, nil
+		}
+		x = xNew
+		fx := f(x)
+		if fx == 0 {
+			return x, nil
+		}
+		// keep the smallest bracket containing the root
+		if (fm > 0) != (fx > 0) {
+			left, fl, right, fr = mid, fm, x, fx
+		} else if (fl > 0) != (fx > 0) {
+			right, fr = x, fx
+		} else {
+			left, fl = x, fx
+		}
+		if left > right {
+			left, fl, right, fr = right, fr, left, fl
+		}
+		if right-left <= rootTolerance(x, absTol, relTol) {
+			return x, nil
+		}
+	}
+	return x, ErrorRootIterations
+}
+
+// Regula falsi with the Illinois modification: the function value at an end
+// of the bracket which is kept twice in a row is halved, so that both ends
+// converge.
+type IllinoisFinder struct{}
+
+func (finder IllinoisFinder) FindRoot(f Func1D, left, right, absTol, relTol float64) (float64, error) {
+	fl, fr := f(left), f(right)
+	if fl == 0 {
+		return left, nil
+	}
+	if fr == 0 {
+		return right, nil
+	}
+	if (fl > 0) == (fr > 0) {
+		return 0.0, ErrorNotBracketed
+	}
+	side := 0
+	x := left
+	for iter := 0; iter < RootFinderMaxIterations; iter++ {
+		x = (left*fr - right*fl) / (fr - fl)
+		if math.Abs(right-left) <= rootTolerance(x, absTol, relTol) {
+			return x, nil
+		}
+		fx := f(x)
+		if fx == 0 {
+			return x, nil
+		}
+		if (fx > 0) == (fr > 0) {
+			right, fr = x, fx
+			if side == -1 {
+				fl /= 2
+			}
+			side = -1
+		} else {
+			left, fl = x, fx
+			if side == 1 {
+				fr /= 2
+			}
+			side = 1
+		}
+	}
+	return x, ErrorRootIterations
+}
+
+// Distance used for the finite difference derivative in NewtonFinder, relative
+// to the bracket width.
+const NewtonDerivativeStep = 1e-7
+
+// Newton's method with a finite difference derivative, safeguarded by the
+// bracket: any step leaving the bracket (or failing to shrink it fast enough)
+// is replaced by bisection.
+type NewtonFinder struct{}
+
+func (finder NewtonFinder) FindRoot(f Func1D, left, right, absTol, relTol float64) (float64, error) {
+	fl, fr := f(left), f(right)
+	if fl == 0 {
+		return left, nil
+	}
+	if fr == 0 {
+		return right, nil
+	}
+	if (fl > 0) == (fr > 0) {
+		return 0.0, ErrorNotBracketed
+	}
+	if fl > 0 {
+		// orient so that f(left) < 0
+		left, right = right, left
+	}
+	h := NewtonDerivativeStep * math.Abs(right-left)
+	x := 0.5 * (left + right)
+	fx := f(x)
+	previousStep := math.Abs(right - left)
+	for iter := 0; iter < RootFinderMaxIterations; iter++ {
+		if fx == 0 {
+			return x, nil
+		}
+		if fx < 0 {
+			left = x
+		} else {
+			right = x
+		}
+		derivative := (f(x+h) - fx) / h
+		xNew := x - fx/derivative
+		step := math.Abs(xNew - x)
+		outside := (xNew-left)*(xNew-right) > 0 || math.IsNaN(xNew) || math.IsInf(xNew, 0)
+		if outside || 2*step > previousStep {
+			xNew = 0.5 * (left + right)
+			step = math.Abs(xNew - x)
+		}
+		previousStep = step
+		x = xNew
+		if step <= rootTolerance(x, absTol, relTol) || math.Abs(right-left) <= rootTolerance(x, absTol, relTol) {
+			return x, nil
+		}
+		fx = f(x)
+	}
+	return x, ErrorRootIterations
+}
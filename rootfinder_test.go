@@ -0,0 +1,98 @@
+package polecalc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Each RootFinder should find the roots of some awkward functions to the
+// requested tolerance.
+func TestRootFinders(t *testing.T) {
+	functions := []Func1D{
+		func(x float64) float64 { return x*x*x - 2*x - 5 },
+		func(x float64) float64 { return math.Cos(x) - x },
+		func(x float64) float64 { return math.Exp(10*x) - 1e3 },
+		func(x float64) float64 { return math.Cbrt(x - 0.1) },
+	}
+	roots := []float64{2.0945514815423265, 0.7390851332151607, math.Log(1e3) / 10, 0.1}
+	finders := []RootFinder{BisectionFinder{}, BrentFinder{}, RiddersFinder{}, IllinoisFinder{}, NewtonFinder{}}
+	for i, f := range functions {
+		for _, finder := range finders {
+			root, err := finder.FindRoot(f, 0.0, 3.0, 1e-12, 0.0)
+			if err != nil {
+				t.Fatalf("%T failed on function %d: %s", finder, i, err)
+			}
+			if math.Abs(root-roots[i]) > 1e-10 {
+				t.Fatalf("%T found root %.15f for function %d; expected %.15f", finder, root, i, roots[i])
+			}
+		}
+	}
+}
+
+func TestRootFinderNoBracket(t *testing.T) {
+	f := func(x float64) float64 { return x*x + 1 }
+	for _, finder := range []RootFinder{BrentFinder{}, RiddersFinder{}, IllinoisFinder{}, NewtonFinder{}} {
+		if _, err := finder.FindRoot(f, -1.0, 1.0, 1e-12, 0.0); !errors.Is(err, ErrorNotBracketed) {
+			t.Fatalf("%T accepted an interval without a root", finder)
+		}
+	}
+}
+
+// With zero tolerances the finders should still stop at a root at 0 where f
+// never vanishes exactly.
+func TestRootFindersRootAtZero(t *testing.T) {
+	f := func(x float64) float64 { return math.Copysign(1+x*x, x) }
+	for _, finder := range []RootFinder{BrentFinder{}, RiddersFinder{}, IllinoisFinder{}, NewtonFinder{}} {
+		root, err := finder.FindRoot(f, -1.0, 2.0, 0.0, 0.0)
+		if err != nil {
+			t.Fatalf("%T failed on a root at 0: %s", finder, err)
+		}
+		if math.Abs(root) > 1e-14 {
+			t.Fatalf("%T found root %e; expected 0", finder, root)
+		}
+	}
+}
+
+// Counts the calls of the wrapped equation's AbsError.
+type countingEquation struct {
+	SelfConsistentEquation
+	calls *int
+}
+
+func (eq countingEquation) AbsError(args interface{}) float64 {
+	*eq.calls++
+	return eq.SelfConsistentEquation.AbsError(args)
+}
+
+// Brent's method should solve the T = 0 system with far fewer evaluations of
+// the D1 equation than full precision bisection, reaching the same solution.
+func TestBrentSolveEvaluations(t *testing.T) {
+	tolerances := []float64{1e-6, 1e-6, 1e-6}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	solveCounting := func(finder RootFinder) (Environment, int) {
+		calls := 0
+		system := NewZeroTempSystem(tolerances)
+		system.Equations[0] = countingEquation{system.Equations[0], &calls}
+		if finder != nil {
+			system.SetRootFinder(finder, []float64{1e-10, 1e-10, 1e-10}, nil)
+		}
+		solution, err := system.Solve(*env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return solution.(Environment), calls
+	}
+	bisection, bisectionCalls := solveCounting(nil)
+	brent, brentCalls := solveCounting(BrentFinder{})
+	if 2*brentCalls > bisectionCalls {
+		t.Fatalf("Brent used %d D1 evaluations; bisection used %d", brentCalls, bisectionCalls)
+	}
+	if math.Abs(brent.D1-bisection.D1) > 1e-6 || math.Abs(brent.Mu-bisection.Mu) > 1e-6 || math.Abs(brent.F0-bisection.F0) > 1e-6 {
+		t.Fatalf("Brent solution differs: got\n%s, expected\n%s", brent.String(), bisection.String())
+	}
+}
@@ -1,19 +1,32 @@
 package polecalc
 
-import "math"
+import (
+	"fmt"
+	"math"
+	"time"
+)
 
-// One-parameter scalar self-consistent equation
-type SelfConsistentEquation interface {
-	// return the absolute error associated with this equation under the given interface{}
-	AbsError(args interface{}) float64
+// One-parameter scalar self-consistent equation on arguments of type T
+type TypedEquation[T any] interface {
+	// return the absolute error associated with this equation under the given args
+	AbsError(args T) float64
 	// set the appropriate variable in args to value
-	SetArguments(value float64, args interface{}) interface{}
+	SetArguments(value float64, args T) T
 	// range of possible values for SetArguments
-	Range(args interface{}) (float64, float64, error)
+	Range(args T) (float64, float64, error)
 }
 
+// One-parameter scalar self-consistent equation on untyped arguments
+type SelfConsistentEquation = TypedEquation[interface{}]
+
 // Return an interface{} which solves eq to tolerance of BisectionFullPrecision
 func Solve(eq SelfConsistentEquation, args interface{}) (interface{}, error) {
+	return SolveWith(eq, args, BisectionFinder{}, 0.0, 0.0)
+}
+
+// Return args modified to solve eq, using finder to locate the root to
+// within max(absTol, relTol*|root|).
+func SolveWith[T any](eq TypedEquation[T], args T, finder RootFinder, absTol, relTol float64) (T, error) {
 	eqError := func(value float64) float64 {
 		args = eq.SetArguments(value, args)
 		return eq.AbsError(args)
@@ -26,7 +39,7 @@ func Solve(eq SelfConsistentEquation, args interface{}) (interface{}, error) {
 	if err != nil {
 		return args, err
 	}
-	solution, err := BisectionFullPrecision(eqError, left, right)
+	solution, err := finder.FindRoot(eqError, left, right, absTol, relTol)
 	if err != nil {
 		return args, err
 	}
@@ -36,6 +49,11 @@ func Solve(eq SelfConsistentEquation, args interface{}) (interface{}, error) {
 
 // Return a slice of interface{}'s which solve eq
 func MultiSolve(eq SelfConsistentEquation, args interface{}) ([]interface{}, error) {
+	return MultiSolveWith(eq, args, BisectionFinder{}, 0.0, 0.0)
+}
+
+// Return a slice of copies of args which solve eq, found as in SolveWith.
+func MultiSolveWith[T any](eq TypedEquation[T], args T, finder RootFinder, absTol, relTol float64) ([]T, error) {
 	eqError := func(value float64) float64 {
 		args = eq.SetArguments(value, args)
 		return eq.AbsError(args)
@@ -48,10 +66,10 @@ func MultiSolve(eq SelfConsistentEquation, args interface{}) ([]interface{}, err
 	if err != nil {
 		return nil, err
 	}
-	solutions := []interface{}{}
+	solutions := []T{}
 	for _, bracket := range brackets {
 		left, right := bracket[0], bracket[1]
-		solution, err := BisectionFullPrecision(eqError, left, right)
+		solution, err := finder.FindRoot(eqError, left, right, absTol, relTol)
 		if err != nil {
 			return solutions, err
 		}
@@ -60,29 +78,92 @@ func MultiSolve(eq SelfConsistentEquation, args interface{}) ([]interface{}, err
 	return solutions, nil
 }
 
-// A group of self-consistent equations which may be coupled and must all be
-// solved for the group to be considered solved.
-type SelfConsistentSystem struct {
-	Equations  []SelfConsistentEquation
-	Tolerances []float64
+// A group of self-consistent equations on arguments of type T which may be
+// coupled and must all be solved for the group to be considered solved.
+type TypedSystem[T any] struct {
+	Equations  []TypedEquation[T]
+	Tolerances []float64 // largest acceptable |AbsError| for each equation
+	// Root finder for the individual equations (BisectionFinder if nil) and
+	// the absolute and relative tolerances on each variable passed to it.
+	// Missing tolerances are 0 (full precision).
+	Finder                               RootFinder
+	RootAbsTolerances, RootRelTolerances []float64
+	// Solve with SolveSimultaneous instead of one equation at a time.
+	Simultaneous bool
+	// Largest number of steps in Solve (DefaultSolveMaxIterations if 0).
+	MaxIterations int
+}
+
+// A group of self-consistent equations on untyped arguments
+type SelfConsistentSystem = TypedSystem[interface{}]
+
+// Add eq to the system with lower priority than the existing equations.
+func (system *TypedSystem[T]) AddEquation(eq TypedEquation[T], tolerance float64) {
+	system.Equations = append(system.Equations, eq)
+	system.Tolerances = append(system.Tolerances, tolerance)
+}
+
+// Use finder for each equation, stopping at the given per-equation absolute
+// and relative tolerances on the variables.
+func (system *TypedSystem[T]) SetRootFinder(finder RootFinder, absTolerances, relTolerances []float64) {
+	system.Finder = finder
+	system.RootAbsTolerances = absTolerances
+	system.RootRelTolerances = relTolerances
+}
+
+// Root finder and tolerances for equation i.
+func (system *TypedSystem[T]) rootFinder(i int) (RootFinder, float64, float64) {
+	finder := system.Finder
+	if finder == nil {
+		finder = BisectionFinder{}
+	}
+	absTol, relTol := 0.0, 0.0
+	if i < len(system.RootAbsTolerances) {
+		absTol = system.RootAbsTolerances[i]
+	}
+	if i < len(system.RootRelTolerances) {
+		relTol = system.RootRelTolerances[i]
+	}
+	return finder, absTol, relTol
 }
 
-// Solve the self-consistent system, returning the resulting interface{}
-func (system *SelfConsistentSystem) Solve(args interface{}) (interface{}, error) {
+// Solve the self-consistent system, returning the resulting args
+func (system *TypedSystem[T]) Solve(args T) (T, error) {
+	solution, _, err := system.SolveWithReport(args)
+	return solution, err
+}
+
+// Solve the self-consistent system, returning the resulting args and the
+// history of the solve.  Gives up with a SolveError if the residuals
+// diverge or return to an earlier state, or after MaxIterations steps.
+func (system *TypedSystem[T]) SolveWithReport(args T) (T, *SolveReport, error) {
+	if system.Simultaneous {
+		return system.solveSimultaneous(args)
+	}
+	report := &SolveReport{}
+	residuals := system.Residuals(args)
 	i := 0
-	for !system.IsSolved(args) {
+	for !system.withinTolerance(residuals, len(system.Equations)-1) {
 		// this should never be true: if it is, failed to iterate
 		if i >= len(system.Equations) {
-			panic("self-consistent system overran bounds")
+			overran := fmt.Errorf("equation index %d out of range for %d equations", i, len(system.Equations))
+			return args, report, &SolveError{SolveOverran, len(report.Steps), overran}
+		}
+		if err := system.checkProgress(report); err != nil {
+			return args, report, err
 		}
+		start := time.Now()
 		// set args to the value that solves the equation
-		newEnv, err := Solve(system.Equations[i], args)
+		finder, absTol, relTol := system.rootFinder(i)
+		newEnv, err := SolveWith(system.Equations[i], args, finder, absTol, relTol)
 		if err != nil {
-			return args, err
+			return args, report, err
 		}
 		args = newEnv
+		residuals = system.Residuals(args)
+		report.Steps = append(report.Steps, SolveStep{i, system.values(args), residuals, time.Since(start)})
 		// check if we need to iterate
-		if !system.solvedUpTo(args, i) {
+		if !system.withinTolerance(residuals, i) {
 			// previous equations have been disturbed; restart
 			i = 0
 		} else {
@@ -90,11 +171,12 @@ func (system *SelfConsistentSystem) Solve(args interface{}) (interface{}, error)
 			i++
 		}
 	}
-	return args, nil
+	report.Converged = true
+	return args, report, nil
 }
 
 // Check if the first (maxIndex + 1) equations are solved
-func (system *SelfConsistentSystem) solvedUpTo(args interface{}, maxIndex int) bool {
+func (system *TypedSystem[T]) solvedUpTo(args T, maxIndex int) bool {
 	for i, eq := range system.Equations {
 		if i > maxIndex {
 			break
@@ -106,8 +188,21 @@ func (system *SelfConsistentSystem) solvedUpTo(args interface{}, maxIndex int) b
 	return true
 }
 
+// Are the first (maxIndex + 1) residuals within tolerance?
+func (system *TypedSystem[T]) withinTolerance(residuals []float64, maxIndex int) bool {
+	for i, r := range residuals {
+		if i > maxIndex {
+			break
+		}
+		if math.Abs(r) > system.Tolerances[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // Are all the self-consistent equations solved?
-func (system *SelfConsistentSystem) IsSolved(args interface{}) bool {
+func (system *TypedSystem[T]) IsSolved(args T) bool {
 	if len(system.Equations) == 0 {
 		return true
 	}
@@ -50,7 +50,7 @@ func TestSystemSolve(t *testing.T) {
 	root1, root2 := 10.0, 20.0
 	eq1, eq2 := LinearEquation{root1, "uno"}, LinearEquation{root2, "dos"}
 	tol1, tol2 := 1e-9, 1e-9
-	system := &SelfConsistentSystem{[]SelfConsistentEquation{eq1, eq2}, []float64{tol1, tol2}}
+	system := &SelfConsistentSystem{Equations: []SelfConsistentEquation{eq1, eq2}, Tolerances: []float64{tol1, tol2}}
 	args := make(map[string]float64)
 	args["uno"] = 0.0
 	args["dos"] = 0.0
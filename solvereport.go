@@ -0,0 +1,171 @@
+package polecalc
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Largest number of steps taken by SelfConsistentSystem.Solve when
+// MaxIterations is not set.
+const DefaultSolveMaxIterations = 1000
+
+// A solve is diverging if its largest scaled residual grows to this multiple
+// of the best seen so far.
+const SolveDivergenceFactor = 1e6
+
+// A solve is oscillating if a step returns to the residuals of an earlier
+// step on the same equation, to within this fraction of each equation's
+// tolerance, without having made progress since: a slowly converging solve
+// also repeats its residuals closely, but its norm keeps shrinking.
+const SolveCycleTolerance = 1e-2
+
+// Reasons for a SolveError.
+const (
+	SolveIterationLimit = "exceeded iteration limit"
+	SolveOscillating    = "oscillating"
+	SolveDiverging      = "diverging"
+	SolveOverran        = "overran the equations"
+)
+
+// Equation index recorded for steps of SolveSimultaneous, which solve all
+// the equations at once.
+const SimultaneousStep = -1
+
+// Error returned when a self-consistent solve is abandoned.
+type SolveError struct {
+	Reason string // one of SolveIterationLimit, SolveOscillating, SolveDiverging, SolveOverran
+	Step   int    // number of steps taken
+	Err    error  // underlying error, if any
+}
+
+func (err *SolveError) Error() string {
+	if err.Err != nil {
+		return fmt.Sprintf("self-consistent solve %s after %d steps: %s", err.Reason, err.Step, err.Err)
+	}
+	return fmt.Sprintf("self-consistent solve %s after %d steps", err.Reason, err.Step)
+}
+
+func (err *SolveError) Unwrap() error {
+	return err.Err
+}
+
+// One step of a self-consistent solve: the equation solved (SimultaneousStep
+// for all of them), then the value of each variable and the residual (AbsError)
+// of each equation afterwards.  Values are NaN for equations which are not
+// ValuedEquations.
+type SolveStep struct {
+	Equation  int
+	Values    []float64
+	Residuals []float64
+	Elapsed   time.Duration
+}
+
+// History of a self-consistent solve.
+type SolveReport struct {
+	Steps     []SolveStep
+	Converged bool
+}
+
+// Total time taken by the steps.
+func (report *SolveReport) Elapsed() time.Duration {
+	var total time.Duration
+	for _, step := range report.Steps {
+		total += step.Elapsed
+	}
+	return total
+}
+
+// Whitespace-separated table with one line per step.
+func (report *SolveReport) String() string {
+	var buffer bytes.Buffer
+	buffer.WriteString("# step\tequation\tvalues\tresiduals\tseconds\n")
+	for i, step := range report.Steps {
+		fmt.Fprintf(&buffer, "%d\t%d\t%v\t%v\t%f\n", i, step.Equation, step.Values, step.Residuals, step.Elapsed.Seconds())
+	}
+	return buffer.String()
+}
+
+// AbsError of each equation under args.
+func (system *TypedSystem[T]) Residuals(args T) []float64 {
+	residuals := make([]float64, len(system.Equations))
+	for i, eq := range system.Equations {
+		residuals[i] = eq.AbsError(args)
+	}
+	return residuals
+}
+
+// Current value of each variable; NaN for equations which are not
+// ValuedEquations.
+func (system *TypedSystem[T]) values(args T) []float64 {
+	values := make([]float64, len(system.Equations))
+	for i, eq := range system.Equations {
+		if valued, ok := eq.(ValuedEquation[T]); ok {
+			values[i] = valued.Value(args)
+		} else {
+			values[i] = math.NaN()
+		}
+	}
+	return values
+}
+
+// Iteration limit for Solve.
+func (system *TypedSystem[T]) maxIterations() int {
+	if system.MaxIterations > 0 {
+		return system.MaxIterations
+	}
+	return DefaultSolveMaxIterations
+}
+
+// Largest residual relative to its tolerance.
+func (system *TypedSystem[T]) scaledResidualNorm(residuals []float64) float64 {
+	norm := 0.0
+	for i, r := range residuals {
+		scaled := math.Abs(r) / system.residualScale(i)
+		if math.IsNaN(scaled) {
+			return math.Inf(1)
+		}
+		norm = math.Max(norm, scaled)
+	}
+	return norm
+}
+
+// Check that the solve recorded in report is still making progress and is
+// under the iteration limit.
+func (system *TypedSystem[T]) checkProgress(report *SolveReport) error {
+	count := len(report.Steps)
+	if count == 0 {
+		return nil
+	}
+	last := report.Steps[count-1]
+	norm := system.scaledResidualNorm(last.Residuals)
+	if math.IsInf(norm, 0) {
+		return &SolveError{SolveDiverging, count, nil}
+	}
+	best := math.Inf(1)
+	for _, previous := range report.Steps[:count-1] {
+		best = math.Min(best, system.scaledResidualNorm(previous.Residuals))
+		if previous.Equation == last.Equation && system.closeResiduals(previous.Residuals, last.Residuals) && norm >= system.scaledResidualNorm(previous.Residuals) {
+			return &SolveError{SolveOscillating, count, nil}
+		}
+	}
+	if norm > SolveDivergenceFactor*best {
+		return &SolveError{SolveDiverging, count, nil}
+	}
+	if count >= system.maxIterations() {
+		return &SolveError{SolveIterationLimit, count, nil}
+	}
+	return nil
+}
+
+// Are the residuals u and v equal to within SolveCycleTolerance times the
+// tolerance of each equation?
+func (system *TypedSystem[T]) closeResiduals(u, v []float64) bool {
+	for i := range u {
+		if !(math.Abs(u[i]-v[i]) <= SolveCycleTolerance*system.residualScale(i)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package polecalc
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// The report of a T = 0 solve should end at the solution.
+func TestSolveReport(t *testing.T) {
+	tolerances := []float64{1e-6, 1e-6, 1e-6}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	for _, simultaneous := range []bool{false, true} {
+		system := NewZeroTempSystem(tolerances)
+		system.Simultaneous = simultaneous
+		solution, report, err := system.SolveWithReport(*env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.Converged || len(report.Steps) == 0 {
+			t.Fatalf("unexpected report (simultaneous = %t):\n%s", simultaneous, report.String())
+		}
+		last := report.Steps[len(report.Steps)-1]
+		solved := solution.(Environment)
+		if last.Values[0] != solved.D1 || last.Values[1] != solved.Mu || last.Values[2] != solved.F0 {
+			t.Fatalf("last step values %v do not match solution %s", last.Values, solved.String())
+		}
+		for i, r := range last.Residuals {
+			if math.Abs(r) > tolerances[i] {
+				t.Fatalf("last step residuals %v not within tolerance", last.Residuals)
+			}
+		}
+		if lines := strings.Count(report.String(), "\n"); lines != len(report.Steps)+1 {
+			t.Fatalf("report table has %d lines for %d steps", lines, len(report.Steps))
+		}
+	}
+}
+
+// Solves which can't converge should stop with the appropriate SolveError.
+func TestSolveErrors(t *testing.T) {
+	// slowly converging: u = 0.99 w + 1, w = 0.99 u
+	slowU := CoupledEquation{"u", func(v map[string]float64) float64 { return v["u"] - 0.99*v["w"] - 1 }, 1e3}
+	slowW := CoupledEquation{"w", func(v map[string]float64) float64 { return v["w"] - 0.99*v["u"] }, 1e3}
+	// residuals start near 5 times the tolerance below and shrink by
+	// 0.999 per cycle: close to the previous cycle, but still converging
+	crawlU := CoupledEquation{"u", func(v map[string]float64) float64 { return v["u"] - 0.9995*v["w"] - 5e-3 }, 1e3}
+	crawlW := CoupledEquation{"w", func(v map[string]float64) float64 { return v["w"] - 0.9995*v["u"] }, 1e3}
+	// cycles between (1, 0), (1, 1), (0, 1) and (0, 0)
+	cycleU := CoupledEquation{"u", func(v map[string]float64) float64 { return v["u"] + v["w"] - 1 }, 10.0}
+	cycleW := CoupledEquation{"w", func(v map[string]float64) float64 { return v["w"] - v["u"] }, 10.0}
+	// the same cycle, drifting by about 1e-7 per turn: well inside the
+	// tolerances below, but not an exact repeat
+	driftU := CoupledEquation{"u", func(v map[string]float64) float64 { return v["u"] + (1+1e-7)*v["w"] - 1 }, 10.0}
+	// each cycle multiplies the residuals by 4
+	growU := CoupledEquation{"u", func(v map[string]float64) float64 { return v["u"] - 2*v["w"] - 1 }, 1e12}
+	growW := CoupledEquation{"w", func(v map[string]float64) float64 { return v["w"] - 2*v["u"] }, 1e12}
+	cases := []struct {
+		equations     []SelfConsistentEquation
+		tolerance     float64
+		maxIterations int
+		reason        string
+	}{
+		{[]SelfConsistentEquation{slowU, slowW}, 1e-9, 10, SolveIterationLimit},
+		{[]SelfConsistentEquation{crawlU, crawlW}, 1e-3, 100, SolveIterationLimit},
+		{[]SelfConsistentEquation{cycleU, cycleW}, 1e-9, 0, SolveOscillating},
+		{[]SelfConsistentEquation{driftU, cycleW}, 1e-3, 0, SolveOscillating},
+		{[]SelfConsistentEquation{growU, growW}, 1e-9, 0, SolveDiverging},
+	}
+	for _, c := range cases {
+		system := &SelfConsistentSystem{Equations: c.equations, Tolerances: []float64{c.tolerance, c.tolerance}, MaxIterations: c.maxIterations}
+		args := map[string]float64{"u": 0.0, "w": 0.0}
+		_, report, err := system.SolveWithReport(args)
+		solveErr, ok := err.(*SolveError)
+		if !ok || solveErr.Reason != c.reason {
+			t.Fatalf("expected %s, got error %v", c.reason, err)
+		}
+		if report.Converged || solveErr.Step != len(report.Steps) {
+			t.Fatalf("inconsistent report for %s:\n%s", c.reason, report.String())
+		}
+	}
+}
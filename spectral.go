@@ -0,0 +1,75 @@
+package polecalc
+
+import "math"
+
+// Spectral function A(k, omega) evaluated on a set of k points and omega
+// values, suitable for comparison with ARPES intensity maps.
+type SpectralGrid struct {
+	Ks     []Vector2
+	Omegas []float64
+	Values [][]float64 // Values[i][j] = A(Ks[i], Omegas[j])
+}
+
+// Evaluate SpectralFunction at every combination of ks and omegas.
+// Points where A is undefined (pole exactly on the real axis) are set to 0.
+func SpectralFunctionGrid(env Environment, ks []Vector2, omegas []float64) (*SpectralGrid, error) {
+	values := make([][]float64, len(ks))
+	for i, k := range ks {
+		values[i] = make([]float64, len(omegas))
+		for j, omega := range omegas {
+			A, err := SpectralFunction(env, k, omega)
+			if err != nil {
+				return nil, err
+			}
+			if math.IsNaN(A) {
+				A = 0.0
+			}
+			values[i][j] = A
+		}
+	}
+	return &SpectralGrid{ks, omegas, values}, nil
+}
+
+// A(k, omega) along the path given by curve.
+func SpectralFunctionCurve(env Environment, curve CurveGenerator, numK uint, omegas []float64) (*SpectralGrid, error) {
+	ks, err := collectKs(func(callback Callback) error {
+		return CallOnCurve(curve, numK, callback)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return SpectralFunctionGrid(env, ks, omegas)
+}
+
+// A(k, omega) along the lines of high symmetry (0, 0) -> (pi, 0) -> (pi, pi) -> (0, 0).
+func SpectralFunctionSymmetryLines(env Environment, numK uint, omegas []float64) (*SpectralGrid, error) {
+	ks, err := collectKs(func(callback Callback) error {
+		return CallOnSymmetryLines(numK, callback)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return SpectralFunctionGrid(env, ks, omegas)
+}
+
+// A(k, omega) over the square mesh with pointsPerSide points on each side.
+func SpectralFunctionPlane(env Environment, pointsPerSide uint32, omegas []float64) (*SpectralGrid, error) {
+	ks, err := collectKs(func(callback Callback) error {
+		return CallOnPlane(pointsPerSide, callback)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return SpectralFunctionGrid(env, ks, omegas)
+}
+
+// Return the k points visited by scan, in order.
+func collectKs(scan func(Callback) error) ([]Vector2, error) {
+	ks := []Vector2{}
+	callback := func(k Vector2) error {
+		ks = append(ks, k)
+		return nil
+	}
+	err := scan(callback)
+	return ks, err
+}
@@ -0,0 +1,61 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// Does SpectralFunction agree with -Im[1/(1/Gc0 - epsilon_k)]/pi evaluated
+// with complex arithmetic?
+func TestSpectralFunctionDyson(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 16
+	env.ImGc0Bins = 128
+	k := Vector2{0.25 * math.Pi, 0.5 * math.Pi}
+	for _, omega := range []float64{-2.0, 0.5, 3.0} {
+		ReGc0, err := ZeroTempReGc0(*env, k, omega)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ImGc0, err := ZeroTempImGc0Point(*env, k, omega)
+		if err != nil {
+			t.Fatal(err)
+		}
+		G := 1 / (1/complex(ReGc0, ImGc0) - complex(ZeroTempElectronEnergy(*env, k), 0))
+		A, err := SpectralFunction(*env, k, omega)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := -imag(G) / math.Pi
+		if math.Abs(A-expected) > 1e-9*math.Max(1, math.Abs(expected)) {
+			t.Fatalf("spectral function at omega = %f incorrect (got %f, expected %f)", omega, A, expected)
+		}
+	}
+}
+
+// Does the grid evaluator produce one row per k and one column per omega?
+func TestSpectralFunctionSymmetryLines(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 8
+	env.ImGc0Bins = 64
+	env.ReGc0Points = 32
+	omegas := MakeRange(-2.0, 2.0, 5)
+	grid, err := SpectralFunctionSymmetryLines(*env, 4, omegas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grid.Ks) != 12 || len(grid.Values) != 12 {
+		t.Fatalf("unexpected number of k points (%d)", len(grid.Ks))
+	}
+	for _, kValues := range grid.Values {
+		if len(kValues) != len(omegas) {
+			t.Fatalf("unexpected number of omega values (%d)", len(kValues))
+		}
+	}
+}
@@ -1,16 +1,14 @@
 package polecalc
 
-import "math"
-
 // Single-hole hopping energy.  Minimum must be 0.
 func Epsilon(env Environment, k Vector2) float64 {
 	return EpsilonBar(env, k) - env.EpsilonMin
 }
 
-// Single-hole hopping energy without fixed minimum.
+// Single-hole hopping energy without fixed minimum, given by the Dispersion
+// selected by env.HoleModel.
 func EpsilonBar(env Environment, k Vector2) float64 {
-	sx, sy := math.Sin(k.X), math.Sin(k.Y)
-	return 2*env.Th()*((sx+sy)*(sx+sy)-1) + 4*(env.D1*env.T0-env.Thp)*sx*sy
+	return env.HoleDispersion().Energy(env, k)
 }
 
 // Find the minimum of EpsilonBar() to help in calculating Epsilon()
@@ -0,0 +1,146 @@
+package polecalc
+
+import (
+	"math"
+	"sync"
+)
+
+// Mesh symmetry operations, acting on the integer coordinates (nx, ny) of
+// the square mesh of length L (k = -pi + n*2pi/L).
+type meshOperation func(nx, ny, L uint64) (uint64, uint64)
+
+// Candidate operations, tested against the Environment by SymmetryOperations.
+// Those using pi - k only map the mesh onto itself for even L.
+var meshOperations = []struct {
+	name     string
+	evenOnly bool
+	op       meshOperation
+}{
+	{"swap", false, func(nx, ny, L uint64) (uint64, uint64) { return ny, nx }},
+	{"inversion", false, func(nx, ny, L uint64) (uint64, uint64) { return (L - nx) % L, (L - ny) % L }},
+	{"mirror-x", false, func(nx, ny, L uint64) (uint64, uint64) { return (L - nx) % L, ny }},
+	{"mirror-y", false, func(nx, ny, L uint64) (uint64, uint64) { return nx, (L - ny) % L }},
+	{"pi-minus-kx", true, func(nx, ny, L uint64) (uint64, uint64) { return (L + L/2 - nx) % L, ny }},
+	{"pi-minus-ky", true, func(nx, ny, L uint64) (uint64, uint64) { return nx, (L + L/2 - ny) % L }},
+	{"shift-pi", true, func(nx, ny, L uint64) (uint64, uint64) { return (nx + L/2) % L, (ny + L/2) % L }},
+}
+
+// Number of mesh points at which each candidate operation is tested.
+const SymmetryTestPoints = 16
+
+// Names of the mesh operations which leave invariant every k-dependent
+// quantity in the D1, mu and F0 sums: the holon dispersion, |g(k)|^2, the
+// spinon energy, the electron energy, and the D1 form factor sin(kx)sin(ky).
+// The operations are not derived from the Dispersion and GapFunction models;
+// invariance is only tested at SymmetryTestPoints scattered mesh points, so
+// a term breaking the symmetry which happens to vanish at all of them is
+// missed.  Only the D1, mu and F0 averages (AverageK) use the result;
+// CallOnPlane and DoGridListen still visit every mesh point.
+func SymmetryOperations(env Environment) []string {
+	L := uint64(env.GridLength)
+	N := L * L
+	probe := func(k Vector2) []float64 {
+		return []float64{EpsilonBar(env, k), GapFormFactorSquared(env, k), ZeroTempOmega(env, k),
+			ZeroTempElectronEnergy(env, k), math.Sin(k.X) * math.Sin(k.Y)}
+	}
+	names := []string{}
+	for _, candidate := range meshOperations {
+		if candidate.evenOnly && L%2 != 0 {
+			continue
+		}
+		invariant := true
+		for j := uint64(0); j < SymmetryTestPoints && invariant; j++ {
+			i := (7919*j + 104729) % N
+			nx, ny := i%L, i/L
+			mx, my := candidate.op(nx, ny, L)
+			before, after := probe(SquareAt(i, env.GridLength)), probe(SquareAt(my*L+mx, env.GridLength))
+			for v := range before {
+				if math.Abs(before[v]-after[v]) > 1e-12*(1+math.Abs(before[v])) {
+					invariant = false
+					break
+				}
+			}
+		}
+		if invariant {
+			names = append(names, candidate.name)
+		}
+	}
+	return names
+}
+
+// A representative point of the irreducible wedge and the number of mesh
+// points in its orbit.
+type wedgePoint struct {
+	index        uint64
+	multiplicity uint64
+}
+
+type wedgeKey struct {
+	L          uint32
+	operations string
+}
+
+var wedgeCache = make(map[wedgeKey][]wedgePoint)
+var wedgeLock sync.Mutex
+
+// Irreducible wedge of the square mesh of length L under the group generated
+// by the named operations.  Results are cached.
+func irreducibleWedge(L uint32, names []string) []wedgePoint {
+	key := wedgeKey{L, ""}
+	for _, name := range names {
+		key.operations += name + ";"
+	}
+	wedgeLock.Lock()
+	defer wedgeLock.Unlock()
+	if wedge, ok := wedgeCache[key]; ok {
+		return wedge
+	}
+	ops := []meshOperation{}
+	for _, candidate := range meshOperations {
+		for _, name := range names {
+			if candidate.name == name {
+				ops = append(ops, candidate.op)
+			}
+		}
+	}
+	L64 := uint64(L)
+	N := L64 * L64
+	seen := make([]bool, N)
+	wedge := []wedgePoint{}
+	for i := uint64(0); i < N; i++ {
+		if seen[i] {
+			continue
+		}
+		// visit the orbit of i; i is the smallest index in it
+		seen[i] = true
+		orbit := []uint64{i}
+		for o := 0; o < len(orbit); o++ {
+			nx, ny := orbit[o]%L64, orbit[o]/L64
+			for _, op := range ops {
+				mx, my := op(nx, ny, L64)
+				j := my*L64 + mx
+				if !seen[j] {
+					seen[j] = true
+					orbit = append(orbit, j)
+				}
+			}
+		}
+		wedge = append(wedge, wedgePoint{i, uint64(len(orbit))})
+	}
+	wedgeCache[key] = wedge
+	return wedge
+}
+
+// Average of worker over the GridLength mesh, evaluated only on the
+// irreducible wedge under the symmetries found by SymmetryOperations.
+// worker must depend on k only through the quantities tested there.
+func SymmetricAverage(env Environment, worker Consumer) float64 {
+	wedge := irreducibleWedge(env.GridLength, SymmetryOperations(env))
+	sum, compensate := 0.0, 0.0
+	for _, p := range wedge {
+		value := float64(p.multiplicity) * worker(SquareAt(p.index, env.GridLength))
+		sum, compensate = KahanSum(value, sum, compensate)
+	}
+	L := float64(env.GridLength)
+	return sum / (L * L)
+}
@@ -0,0 +1,66 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// Summing over the irreducible wedge should reproduce the full-mesh D1, mu
+// and F0 errors, with about 8 times fewer points for the default model.
+func TestSymmetricAverage(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 16
+	env.Initialize()
+	checkSymmetricErrors(t, *env)
+	wedge := irreducibleWedge(env.GridLength, SymmetryOperations(*env))
+	if N := int(env.GridLength * env.GridLength); len(wedge) > N/6 {
+		t.Fatalf("wedge has %d of %d points; expected about 1/8", len(wedge), N)
+	}
+}
+
+// An anisotropic electron band breaks the kx <-> ky symmetry, which must
+// then not be used.
+func TestSymmetryBroken(t *testing.T) {
+	env, err := EnvironmentFromString(`{"GridLength":16, "InitD1":0.1,
+		"InitMu":-0.1, "InitF0":0.1, "Alpha":-1, "T0":1.0, "Tz":0.1,
+		"Thp":0.1, "X":0.1, "ElectronModel":"table",
+		"ElectronHoppings":[{"X":1, "Y":0, "T":0.4}, {"X":0, "Y":1, "T":0.3}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	for _, name := range SymmetryOperations(*env) {
+		if name == "swap" {
+			t.Fatal("swap symmetry used for anisotropic band")
+		}
+	}
+	checkSymmetricErrors(t, *env)
+}
+
+func checkSymmetricErrors(t *testing.T, env Environment) {
+	errors := func(e Environment) []float64 {
+		return []float64{ZeroTempD1AbsError(e), ZeroTempMuAbsError(e), ZeroTempF0AbsError(e)}
+	}
+	full := errors(env)
+	env.Symmetrize = true
+	symmetric := errors(env)
+	for i := range full {
+		if math.Abs(full[i]-symmetric[i]) > 1e-12 {
+			t.Fatalf("symmetric sum %d differs (full %e, symmetric %e)", i, full[i], symmetric[i])
+		}
+	}
+}
+
+// The irreducible wedge isn't used by adaptive refinement, so asking for
+// both should be rejected.
+func TestSymmetrizeRejectsAdaptive(t *testing.T) {
+	_, err := EnvironmentFromString(`{"GridLength":16, "InitD1":0.1,
+		"InitMu":-0.1, "InitF0":0.1, "Alpha":-1, "T0":1.0, "Tz":0.1,
+		"Thp":0.1, "X":0.1, "Symmetrize":true, "AdaptiveTolerance":1e-6}`)
+	if err == nil {
+		t.Fatal("accepted Symmetrize with AdaptiveTolerance")
+	}
+}
@@ -0,0 +1,163 @@
+package polecalc
+
+import "errors"
+
+// Values of Environment.ImGc0Method.  An empty name selects HistogramMethod.
+const (
+	HistogramMethod = "histogram" // each delta function goes into one bin (DeltaBinner)
+	TriangleMethod  = "triangle"  // linear interpolation over triangles (TriangleBin)
+)
+
+func checkImGc0Method(name string) error {
+	if name == "" || name == HistogramMethod || name == TriangleMethod {
+		return nil
+	}
+	return errors.New("unknown ImGc0 method " + name)
+}
+
+// Linear triangle method for the delta function terms given by deltaTerms.
+// Each square of the mesh is split into two triangles, the energy of each
+// term is interpolated linearly over the triangle, and the resulting
+// piecewise-linear spectral weight is integrated exactly over each bin.
+// Coefficients are averaged over the triangle's vertices.  Terms are matched
+// between vertices by their index in the slices returned by deltaTerms.
+// The result is a weight per bin normalized as in DeltaBin, with bins from
+// BinVarValues(binStart, binStop, numBins); weight outside that range is
+// dropped.  Both the evaluation of deltaTerms and the binning are split over
+// GridWorkers goroutines as in DoGridListen.
+func TriangleBin(pointsPerSide uint32, deltaTerms DeltaTermsFunc, binStart, binStop float64, numBins uint) []float64 {
+	N := uint64(pointsPerSide) * uint64(pointsPerSide)
+	tb := triangleBinner{NewDeltaBinner(deltaTerms, binStart, binStop, numBins), pointsPerSide,
+		make([][]float64, N), make([][]float64, N), nil, nil}
+	// every worker writes its own points of the shared tables
+	evaluateAt := func(listener GridListener, i uint64) GridListener {
+		tb := listener.(triangleBinner)
+		tb.omegas[i], tb.coeffs[i] = deltaTerms(SquareAt(i, pointsPerSide))
+		return tb
+	}
+	listenInChunks(N, tb, evaluateAt)
+	binSquareAt := func(listener GridListener, i uint64) GridListener {
+		return listener.(triangleBinner).addSquare(i)
+	}
+	return listenInChunks(N, tb, binSquareAt).result().([]float64)
+}
+
+// GridListener for TriangleBin.  Points are passed by index through
+// listenInChunks, since each square needs the terms at its corners;
+// omegas and coeffs are shared by all forks.
+type triangleBinner struct {
+	binner            *DeltaBinner // bin layout
+	pointsPerSide     uint32
+	omegas, coeffs    [][]float64 // delta terms at each mesh point
+	bins, compensates []float64
+}
+
+func (tb triangleBinner) initialize() GridListener {
+	tb.bins = make([]float64, tb.binner.numBins)
+	tb.compensates = make([]float64, tb.binner.numBins)
+	return tb
+}
+
+// Points are only passed by index (see TriangleBin).
+func (tb triangleBinner) grab(point Vector2) GridListener {
+	panic("triangleBinner must be given mesh indices")
+}
+
+func (tb triangleBinner) result() interface{} {
+	return tb.bins
+}
+
+func (tb triangleBinner) fork() GridListener {
+	return tb.initialize()
+}
+
+func (tb triangleBinner) merge(other GridListener) GridListener {
+	o := other.(triangleBinner)
+	for i := range tb.bins {
+		tb.bins[i], tb.compensates[i] = kahanMerge(tb.bins[i], tb.compensates[i], o.bins[i], o.compensates[i])
+	}
+	return tb
+}
+
+// Add the two triangles of the square with lower left corner at mesh index i.
+func (tb triangleBinner) addSquare(i uint64) GridListener {
+	L := uint64(tb.pointsPerSide)
+	nx, ny := i%L, i/L
+	// corners of the square, wrapping around the zone edge
+	a := i
+	b := ny*L + (nx+1)%L
+	c := ((ny+1)%L)*L + nx
+	d := ((ny+1)%L)*L + (nx+1)%L
+	weight := 1.0 / float64(2*L*L)
+	for _, tri := range [][3]uint64{{a, b, d}, {a, c, d}} {
+		for t := range tb.omegas[a] {
+			es := [3]float64{tb.omegas[tri[0]][t], tb.omegas[tri[1]][t], tb.omegas[tri[2]][t]}
+			coeff := (tb.coeffs[tri[0]][t] + tb.coeffs[tri[1]][t] + tb.coeffs[tri[2]][t]) / 3.0
+			addTriangle(tb.binner, tb.bins, tb.compensates, es, coeff*weight)
+		}
+	}
+	return tb
+}
+
+// Add weight spread over omega as by a linear function on a triangle with
+// the vertex values es.  The part of the weight outside the bin range is
+// dropped.
+func addTriangle(binner *DeltaBinner, bins, compensates []float64, es [3]float64, weight float64) {
+	e1, e2, e3 := sort3(es[0], es[1], es[2])
+	first, last := binner.BinVarToIndex(e1), binner.BinVarToIndex(e3)
+	start, stop := first, last
+	if start < 0 {
+		start = 0
+	}
+	if stop >= len(bins) {
+		stop = len(bins) - 1
+	}
+	if start > stop {
+		return
+	}
+	if first == last {
+		bins[first], compensates[first] = KahanSum(weight, bins[first], compensates[first])
+		return
+	}
+	step := binner.Step()
+	previous := 0.0
+	if start != first {
+		previous = triangleFraction(e1, e2, e3, binner.IndexToBinVar(start))
+	}
+	for n := start; n <= stop; n++ {
+		fraction := 1.0
+		if n < last {
+			fraction = triangleFraction(e1, e2, e3, binner.IndexToBinVar(n)+step)
+		}
+		bins[n], compensates[n] = KahanSum(weight*(fraction-previous), bins[n], compensates[n])
+		previous = fraction
+	}
+}
+
+// Fraction of the area of a triangle with linear energy and vertex energies
+// e1 <= e2 <= e3 where the energy is below omega.
+func triangleFraction(e1, e2, e3, omega float64) float64 {
+	if omega <= e1 {
+		return 0.0
+	}
+	if omega >= e3 {
+		return 1.0
+	}
+	if omega <= e2 {
+		return (omega - e1) * (omega - e1) / ((e2 - e1) * (e3 - e1))
+	}
+	return 1.0 - (e3-omega)*(e3-omega)/((e3-e1)*(e3-e2))
+}
+
+func sort3(x, y, z float64) (float64, float64, float64) {
+	if x > y {
+		x, y = y, x
+	}
+	if y > z {
+		y, z = z, y
+	}
+	if x > y {
+		x, y = y, x
+	}
+	return x, y, z
+}
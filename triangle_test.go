@@ -0,0 +1,100 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// The triangle method moves weight between bins but should not change the
+// total.
+func TestTriangleBinTotalWeight(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength, env.ImGc0Bins = 16, 64
+	k := Vector2{0.3, 1.1}
+	_, histogram := ZeroTempImGc0(*env, k)
+	env.ImGc0Method = TriangleMethod
+	_, triangle := ZeroTempImGc0(*env, k)
+	histSum, triSum := 0.0, 0.0
+	for i := range histogram {
+		histSum += histogram[i]
+		triSum += triangle[i]
+	}
+	if math.Abs(histSum-triSum) > 1e-12 {
+		t.Fatalf("triangle method changed the total weight (histogram %e, triangle %e)", histSum, triSum)
+	}
+}
+
+// On the same grid, the triangle method should be closer than histogram
+// binning to a converged (fine grid) ImGc0.  The bins are held fixed between
+// grids.
+func TestTriangleBinGridConvergence(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := Vector2{0.3, 1.1}
+	deltaTerms := func(q Vector2) ([]float64, []float64) {
+		return deltaTermsGc0(*env, k, q)
+	}
+	env.GridLength = 32
+	omegaMin, omegaMax := imGc0OmegaRange(*env, k)
+	numBins := uint(64)
+	converged := TriangleBin(128, deltaTerms, omegaMin, omegaMax, numBins)
+	distance := func(values []float64) float64 {
+		diff := 0.0
+		for i := range values {
+			diff += math.Abs(values[i] - converged[i])
+		}
+		return diff
+	}
+	histogram := distance(DeltaBin(32, NewDeltaBinner(deltaTerms, omegaMin, omegaMax, numBins)))
+	triangle := distance(TriangleBin(32, deltaTerms, omegaMin, omegaMax, numBins))
+	if triangle >= 0.5*histogram {
+		t.Fatalf("triangle method not closer to converged ImGc0 (histogram %e, triangle %e)", histogram, triangle)
+	}
+}
+
+// Splitting the triangle method over several workers should only change the
+// rounding.
+func TestTriangleBinParallel(t *testing.T) {
+	defer func(workers uint16) { GridWorkers = workers }(GridWorkers)
+	deltaTerms := func(q Vector2) ([]float64, []float64) {
+		return []float64{math.Cos(q.X) + math.Cos(q.Y), math.Sin(q.X) * math.Sin(q.Y)}, []float64{1.0, 0.5}
+	}
+	GridWorkers = 1
+	serial := TriangleBin(24, deltaTerms, -3.0, 3.0, 32)
+	GridWorkers = 5
+	parallel := TriangleBin(24, deltaTerms, -3.0, 3.0, 32)
+	for i := range serial {
+		if math.Abs(parallel[i]-serial[i]) > 1e-14 {
+			t.Fatalf("parallel bin %d differs from serial (%e, %e)", i, parallel[i], serial[i])
+		}
+	}
+}
+
+// The triangle method has no broadening, so asking for both is an error.
+func TestTriangleBroadeningRejected(t *testing.T) {
+	if _, err := EnvironmentFromString(`{"ImGc0Method":"triangle", "Broadening":"gaussian", "BroadeningWidth":0.1}`); err == nil {
+		t.Fatal("broadening accepted with the triangle method")
+	}
+}
+
+// Energies outside the bin range should drop the weight there instead of
+// indexing past the bins.  omega = 4 cos(kx) runs past both ends of (0, 2)
+// and lies inside it for 1/6 of the zone.
+func TestTriangleBinOutOfRange(t *testing.T) {
+	deltaTerms := func(q Vector2) ([]float64, []float64) {
+		return []float64{4 * math.Cos(q.X)}, []float64{1.0}
+	}
+	bins := TriangleBin(16, deltaTerms, 0.0, 2.0, 8)
+	total := 0.0
+	for _, b := range bins {
+		total += b
+	}
+	if math.Abs(total-1.0/6.0) > 1e-2 {
+		t.Fatalf("unexpected weight %f inside bin range", total)
+	}
+}
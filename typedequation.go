@@ -0,0 +1,81 @@
+package polecalc
+
+import "math"
+
+// --- adapters between typed and untyped equations ---
+
+// Adapt eq to a SelfConsistentEquation so that it can be solved alongside
+// untyped equations.  The args it is given must hold a T.
+func Untyped[T any](eq TypedEquation[T]) SelfConsistentEquation {
+	return untypedEquation[T]{eq}
+}
+
+type untypedEquation[T any] struct {
+	eq TypedEquation[T]
+}
+
+func (adapter untypedEquation[T]) AbsError(args interface{}) float64 {
+	return adapter.eq.AbsError(args.(T))
+}
+
+func (adapter untypedEquation[T]) SetArguments(value float64, args interface{}) interface{} {
+	return adapter.eq.SetArguments(value, args.(T))
+}
+
+func (adapter untypedEquation[T]) Range(args interface{}) (float64, float64, error) {
+	return adapter.eq.Range(args.(T))
+}
+
+func (adapter untypedEquation[T]) Value(args interface{}) float64 {
+	if valued, ok := adapter.eq.(ValuedEquation[T]); ok {
+		return valued.Value(args.(T))
+	}
+	return math.NaN()
+}
+
+// Adapt the untyped eq to a TypedEquation[T].  Its SetArguments must return a
+// T when given one.
+func Typed[T any](eq SelfConsistentEquation) TypedEquation[T] {
+	return typedEquation[T]{eq}
+}
+
+type typedEquation[T any] struct {
+	eq SelfConsistentEquation
+}
+
+func (adapter typedEquation[T]) AbsError(args T) float64 {
+	return adapter.eq.AbsError(args)
+}
+
+func (adapter typedEquation[T]) SetArguments(value float64, args T) T {
+	return adapter.eq.SetArguments(value, args).(T)
+}
+
+func (adapter typedEquation[T]) Range(args T) (float64, float64, error) {
+	return adapter.eq.Range(args)
+}
+
+func (adapter typedEquation[T]) Value(args T) float64 {
+	if valued, ok := adapter.eq.(ValuedEquation[interface{}]); ok {
+		return valued.Value(args)
+	}
+	return math.NaN()
+}
+
+// SelfConsistentSystem with the same equations and settings as system, each
+// equation adapted with Untyped.
+func UntypedSystem[T any](system *TypedSystem[T]) *SelfConsistentSystem {
+	equations := make([]SelfConsistentEquation, len(system.Equations))
+	for i, eq := range system.Equations {
+		equations[i] = Untyped(eq)
+	}
+	return &SelfConsistentSystem{
+		Equations:         equations,
+		Tolerances:        system.Tolerances,
+		Finder:            system.Finder,
+		RootAbsTolerances: system.RootAbsTolerances,
+		RootRelTolerances: system.RootRelTolerances,
+		Simultaneous:      system.Simultaneous,
+		MaxIterations:     system.MaxIterations,
+	}
+}
@@ -0,0 +1,106 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+type pairArgs struct {
+	U, W float64
+}
+
+// u = 0.5 w + 1
+type pairEquationU struct{}
+
+func (eq pairEquationU) AbsError(args pairArgs) float64 {
+	return args.U - 0.5*args.W - 1
+}
+
+func (eq pairEquationU) SetArguments(u float64, args pairArgs) pairArgs {
+	args.U = u
+	return args
+}
+
+func (eq pairEquationU) Value(args pairArgs) float64 {
+	return args.U
+}
+
+func (eq pairEquationU) Range(args pairArgs) (float64, float64, error) {
+	return -10.0, 10.0, nil
+}
+
+// w = 0.5 u
+type pairEquationW struct{}
+
+func (eq pairEquationW) AbsError(args pairArgs) float64 {
+	return args.W - 0.5*args.U
+}
+
+func (eq pairEquationW) SetArguments(w float64, args pairArgs) pairArgs {
+	args.W = w
+	return args
+}
+
+func (eq pairEquationW) Range(args pairArgs) (float64, float64, error) {
+	return -10.0, 10.0, nil
+}
+
+// Typed systems and their untyped adapters should reach the same solution
+// u = 4/3, w = 2/3 with either solver.
+func TestTypedSystem(t *testing.T) {
+	for _, simultaneous := range []bool{false, true} {
+		system := &TypedSystem[pairArgs]{Equations: []TypedEquation[pairArgs]{pairEquationU{}, pairEquationW{}}, Tolerances: []float64{1e-12, 1e-12}, Simultaneous: simultaneous}
+		typed, err := system.Solve(pairArgs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(typed.U-4.0/3.0) > 1e-11 || math.Abs(typed.W-2.0/3.0) > 1e-11 {
+			t.Fatalf("incorrect typed solution %v (simultaneous = %t)", typed, simultaneous)
+		}
+		untyped, err := UntypedSystem(system).Solve(pairArgs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if untyped.(pairArgs) != typed {
+			t.Fatalf("untyped solution %v differs from typed solution %v", untyped, typed)
+		}
+	}
+}
+
+// An untyped equation adapted with Typed should solve as before.
+func TestTypedAdapter(t *testing.T) {
+	root := 10.0
+	eq := Typed[map[string]float64](LinearEquation{root, "uno"})
+	solution, err := SolveWith(eq, map[string]float64{"uno": 0.0}, BisectionFinder{}, 0.0, 0.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(solution["uno"]-root) > MachEpsFloat64() {
+		t.Fatalf("solution not found to expected precision")
+	}
+	if value := eq.(ValuedEquation[map[string]float64]).Value(solution); !math.IsNaN(value) {
+		t.Fatalf("expected NaN value from equation without Value (got %f)", value)
+	}
+}
+
+// The typed T = 0 system should give exactly the untyped solution.
+func TestTypedZeroTempSystem(t *testing.T) {
+	tolerances := []float64{1e-6, 1e-6, 1e-6}
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Initialize()
+	typed, err := NewZeroTempTypedSystem(tolerances).Solve(*env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untyped, err := NewZeroTempSystem(tolerances).Solve(*env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solved := untyped.(Environment)
+	if typed.D1 != solved.D1 || typed.Mu != solved.Mu || typed.F0 != solved.F0 {
+		t.Fatalf("typed solution\n%s differs from untyped solution\n%s", typed.String(), solved.String())
+	}
+}
@@ -40,3 +40,44 @@ func (v Vector2) Equals(u Vector2) bool {
 func (v Vector2) String() string {
 	return fmt.Sprintf("(%f, %f)", v.X, v.Y)
 }
+
+type Vector3 struct {
+	X, Y, Z float64
+}
+
+func (v Vector3) Add(u Vector3) Vector3 {
+	return Vector3{v.X + u.X, v.Y + u.Y, v.Z + u.Z}
+}
+
+func (v Vector3) Sub(u Vector3) Vector3 {
+	return Vector3{v.X - u.X, v.Y - u.Y, v.Z - u.Z}
+}
+
+func (v Vector3) Mult(s float64) Vector3 {
+	return Vector3{s * v.X, s * v.Y, s * v.Z}
+}
+
+func (v Vector3) Dot(u Vector3) float64 {
+	return v.X*u.X + v.Y*u.Y + v.Z*u.Z
+}
+
+func (v Vector3) Norm() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+func (v Vector3) NormSquared() float64 {
+	return v.Dot(v)
+}
+
+func (v Vector3) Equals(u Vector3) bool {
+	return v.X == u.X && v.Y == u.Y && v.Z == u.Z
+}
+
+// In-plane (kx, ky) part of v
+func (v Vector3) XY() Vector2 {
+	return Vector2{v.X, v.Y}
+}
+
+func (v Vector3) String() string {
+	return fmt.Sprintf("(%f, %f, %f)", v.X, v.Y, v.Z)
+}
@@ -3,15 +3,20 @@ package polecalc
 import "math"
 
 // Returns the system of equations needed to solve the system at T = 0
-func NewZeroTempSystem(tolerances []float64) *SelfConsistentSystem {
+func NewZeroTempTypedSystem(tolerances []float64) *TypedSystem[Environment] {
 	eqD1 := ZeroTempD1Equation{}
 	eqMu := ZeroTempMuEquation{}
 	eqF0 := ZeroTempF0Equation{}
-	equations := []SelfConsistentEquation{eqD1, eqMu, eqF0}
-	system := &SelfConsistentSystem{equations, tolerances}
+	equations := []TypedEquation[Environment]{eqD1, eqMu, eqF0}
+	system := &TypedSystem[Environment]{Equations: equations, Tolerances: tolerances}
 	return system
 }
 
+// NewZeroTempTypedSystem with untyped arguments
+func NewZeroTempSystem(tolerances []float64) *SelfConsistentSystem {
+	return UntypedSystem(NewZeroTempTypedSystem(tolerances))
+}
+
 // --- D1 equation ---
 
 // D1 = -1/(2N) \sum_k (1 - xi(k)/E(k)) * sin(kx) * sin(ky)
@@ -20,25 +25,28 @@ func ZeroTempD1AbsError(env Environment) float64 {
 		sx, sy := math.Sin(k.X), math.Sin(k.Y)
 		return -0.5 * (1 - Xi(env, k)/ZeroTempPairEnergy(env, k)) * sx * sy
 	}
-	return env.D1 - Average(env.GridLength, worker)
+	return env.D1 - AverageK(env, worker)
 }
 
 type ZeroTempD1Equation struct{}
 
-func (eq ZeroTempD1Equation) AbsError(args interface{}) float64 {
+func (eq ZeroTempD1Equation) AbsError(env Environment) float64 {
 	//println("in d1")
-	return ZeroTempD1AbsError(args.(Environment))
+	return ZeroTempD1AbsError(env)
 }
 
-func (eq ZeroTempD1Equation) SetArguments(D1 float64, args interface{}) interface{} {
-	env := args.(Environment)
+func (eq ZeroTempD1Equation) SetArguments(D1 float64, env Environment) Environment {
 	env.D1 = D1
 	// Epsilon depends on D1 so we may have changed the minimum
 	env.EpsilonMin = EpsilonMin(env)
 	return env
 }
 
-func (eq ZeroTempD1Equation) Range(args interface{}) (float64, float64, error) {
+func (eq ZeroTempD1Equation) Value(env Environment) float64 {
+	return env.D1
+}
+
+func (eq ZeroTempD1Equation) Range(env Environment) (float64, float64, error) {
 	return 0.0, 1.0, nil
 }
 
@@ -49,80 +57,92 @@ func ZeroTempMuAbsError(env Environment) float64 {
 	worker := func(k Vector2) float64 {
 		return 0.5 * (1 - Xi(env, k)/ZeroTempPairEnergy(env, k))
 	}
-	return env.X - Average(env.GridLength, worker)
+	return env.X - AverageK(env, worker)
 }
 
 type ZeroTempMuEquation struct{}
 
-func (eq ZeroTempMuEquation) AbsError(args interface{}) float64 {
+func (eq ZeroTempMuEquation) AbsError(env Environment) float64 {
 	//println("in mu")
-	return ZeroTempMuAbsError(args.(Environment))
+	return ZeroTempMuAbsError(env)
 }
 
-func (eq ZeroTempMuEquation) SetArguments(Mu float64, args interface{}) interface{} {
-	env := args.(Environment)
+func (eq ZeroTempMuEquation) SetArguments(Mu float64, env Environment) Environment {
 	env.Mu = Mu
 	return env
 }
 
+func (eq ZeroTempMuEquation) Value(env Environment) float64 {
+	return env.Mu
+}
+
 // mu < 0 is enforced since for mu >= 0 terms with 1 / PairEnergy() can blow up
 // Factor of -2 is arbitrary, may need to be enlarged for some Environments
-func (eq ZeroTempMuEquation) Range(args interface{}) (float64, float64, error) {
-	env := args.(Environment)
+func (eq ZeroTempMuEquation) Range(env Environment) (float64, float64, error) {
 	return -2 * env.T0, -MachEpsFloat64(), nil
 }
 
 // --- F0 equation ---
 
-// 1/(t0+tz) = 1/N \sum_k (sin(kx) + alpha*sin(ky))^2 / E(k)
+// 1/(t0+tz) = 1/N \sum_k |g(k)|^2 / E(k)
+// g(k) is the gap form factor (sin(kx) + alpha*sin(ky) by default)
 func ZeroTempF0AbsError(env Environment) float64 {
 	worker := func(k Vector2) float64 {
-		sinPart := math.Sin(k.X) + float64(env.Alpha)*math.Sin(k.Y)
-		return sinPart * sinPart / ZeroTempPairEnergy(env, k)
+		return GapFormFactorSquared(env, k) / ZeroTempPairEnergy(env, k)
 	}
-	return 1/(env.T0+env.Tz) - Average(env.GridLength, worker)
+	return 1/(env.T0+env.Tz) - AverageK(env, worker)
 }
 
 type ZeroTempF0Equation struct{}
 
-func (eq ZeroTempF0Equation) AbsError(args interface{}) float64 {
-	//println("in f0", args.(Environment).Mu)
-	return ZeroTempF0AbsError(args.(Environment))
+func (eq ZeroTempF0Equation) AbsError(env Environment) float64 {
+	//println("in f0", env.Mu)
+	return ZeroTempF0AbsError(env)
 }
 
-func (eq ZeroTempF0Equation) SetArguments(F0 float64, args interface{}) interface{} {
-	env := args.(Environment)
+func (eq ZeroTempF0Equation) SetArguments(F0 float64, env Environment) Environment {
 	env.F0 = F0
 	return env
 }
 
-func (eq ZeroTempF0Equation) Range(args interface{}) (float64, float64, error) {
+func (eq ZeroTempF0Equation) Value(env Environment) float64 {
+	return env.F0
+}
+
+func (eq ZeroTempF0Equation) Range(env Environment) (float64, float64, error) {
 	return 0.0, 1.0, nil
 }
 
 // --- energy scales and related functions ---
 
-// Holon (pair?) gap energy.
-func ZeroTempDelta(env Environment, k Vector2) float64 {
-	sx, sy := math.Sin(k.X), math.Sin(k.Y)
-	return 4 * env.F0 * (env.T0 + env.Tz) * (sx + float64(env.Alpha)*sy)
+// Holon (pair?) gap energy: real and imaginary parts of 4F0(t0+tz)g(k).
+func ZeroTempDelta(env Environment, k Vector2) (float64, float64) {
+	re, im := env.Gap().FormFactor(env, k)
+	scale := 4 * env.F0 * (env.T0 + env.Tz)
+	return scale * re, scale * im
 }
 
 // Energy of a pair of holes.
 func ZeroTempPairEnergy(env Environment, k Vector2) float64 {
 	xi := Xi(env, k)
-	delta := ZeroTempDelta(env, k)
-	return math.Sqrt(xi*xi + delta*delta)
+	deltaRe, deltaIm := ZeroTempDelta(env, k)
+	return math.Sqrt(xi*xi + deltaRe*deltaRe + deltaIm*deltaIm)
 }
 
-// Energy of a singlet (?)
+// Spinon energy omega_q^2 = lambda^2 + CS^2*(1 - (sx + sy)^2/2).  With lambda
+// fixed at sqrt(DeltaS^2 + CS^2) this is DeltaS^2 + CS^2*(2 - (sx + sy)^2/2);
+// with FreeLambda the spinon gap is set by the solved lambda instead of DeltaS.
 func ZeroTempOmega(env Environment, k Vector2) float64 {
+	if env.FreeLambda {
+		lambda := env.Lambda()
+		return math.Sqrt(lambda*lambda + math.Pow(env.CS, 2.0)*(1-0.5*math.Pow(math.Sin(k.X)+math.Sin(k.Y), 2.0)))
+	}
 	return math.Sqrt(math.Pow(env.DeltaS, 2.0) + math.Pow(env.CS, 2.0)*(2-0.5*math.Pow(math.Sin(k.X)+math.Sin(k.Y), 2.0)))
 }
 
-// Energy of a physical electron
+// Energy of a physical electron (Dispersion selected by env.ElectronModel)
 func ZeroTempElectronEnergy(env Environment, k Vector2) float64 {
-	return -2.0 * env.T * (math.Cos(k.X) + math.Cos(k.Y))
+	return env.ElectronDispersion().Energy(env, k)
 }
 
 // Fermi distribution at T = 0 is H(-x), where H is a step function.
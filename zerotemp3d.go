@@ -0,0 +1,84 @@
+package polecalc
+
+import "math"
+
+// --- three-dimensional Brillouin zone ---
+// The holon gap and the physical electron dispersion pick up an interlayer
+// cos(kz) term, through Tz and Tez respectively.  The gap reduces to the 2D
+// form at kz = 0, and the electron energy does when Tez = 0.  Spinon and
+// in-plane holon hopping energies are unchanged.
+
+// Holon pair gap with interlayer hopping: the 2D ZeroTempDelta has kz = 0.
+func ZeroTemp3DDelta(env Environment, k Vector3) (float64, float64) {
+	re, im := env.Gap().FormFactor(env, k.XY())
+	scale := 4 * env.F0 * (env.T0 + env.Tz*math.Cos(k.Z))
+	return scale * re, scale * im
+}
+
+func ZeroTemp3DPairEnergy(env Environment, k Vector3) float64 {
+	xi := Xi(env, k.XY())
+	deltaRe, deltaIm := ZeroTemp3DDelta(env, k)
+	return math.Sqrt(xi*xi + deltaRe*deltaRe + deltaIm*deltaIm)
+}
+
+// Physical electron energy with interlayer hopping Tez: -2Tez*cos(kz) added
+// to the 2D dispersion.
+func ZeroTemp3DElectronEnergy(env Environment, k Vector3) float64 {
+	return ZeroTempElectronEnergy(env, k.XY()) - 2.0*env.Tez*math.Cos(k.Z)
+}
+
+func deltaTerms3DGc0(env Environment, k Vector3, q Vector3) ([]float64, []float64) {
+	bose := func(energy float64) float64 {
+		return 0.0
+	}
+	omega_q := ZeroTempOmega(env, q.XY())
+	E_h := ZeroTemp3DPairEnergy(env, q.Sub(k))
+	xi := Xi(env, q.Sub(k).XY())
+	return energyDeltaTermsGc0(env, omega_q, E_h, xi, bose, ZeroTempFermi)
+}
+
+// ImGc0 at 3D k with the q sum taken over the cubic mesh of GridLength
+// points per side.  Same output as ZeroTempImGc0.
+func ZeroTemp3DImGc0(env Environment, k Vector3) ([]float64, []float64) {
+	var energyMax float64
+	if env.Superconducting {
+		pairWorker := func(q Vector3) float64 {
+			return ZeroTemp3DPairEnergy(env, q.Sub(k))
+		}
+		energyMax = Maximum3D(env.GridLength, pairWorker)
+	} else {
+		xiWorker := func(q Vector3) float64 {
+			return Xi(env, q.Sub(k).XY())
+		}
+		energyMax = Maximum3D(env.GridLength, xiWorker)
+	}
+	maxAbsOmega := env.Lambda() + energyMax
+	deltaTerms := func(q Vector3) ([]float64, []float64) {
+		return deltaTerms3DGc0(env, k, q)
+	}
+	return DeltaBin3D(env.GridLength, deltaTerms, -maxAbsOmega-1.0, maxAbsOmega+1.0, env.ImGc0Bins)
+}
+
+// kz-resolved spectral function A(k, omega) = -Im[G(k, omega)]/pi of the
+// full Green's function at each of omegas.
+func ZeroTemp3DSpectralFunction(env Environment, k Vector3, omegas []float64) ([]float64, error) {
+	imOmegas, imValues := ZeroTemp3DImGc0(env, k)
+	imPart, err := NewCubicSpline(imOmegas, imValues)
+	if err != nil {
+		return nil, err
+	}
+	epsilon_k := ZeroTemp3DElectronEnergy(env, k)
+	values := make([]float64, len(omegas))
+	for i, omega := range omegas {
+		ReGc0, err := splineReGc0(env, imPart, omega)
+		if err != nil {
+			return nil, err
+		}
+		ImGc0, err := splineImGc0Point(imPart, omega)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = -fullImGcFromGc0(epsilon_k, ReGc0, ImGc0) / math.Pi
+	}
+	return values, nil
+}
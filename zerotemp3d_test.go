@@ -0,0 +1,46 @@
+package polecalc
+
+import (
+	"math"
+	"testing"
+)
+
+// With Tz = 0 nothing depends on kz, so the 3D ImGc0 should match the 2D one.
+func TestZeroTemp3DImGc0NoInterlayer(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 8
+	env.ImGc0Bins = 64
+	env.Tz = 0.0
+	k := Vector2{0.25 * math.Pi, 0.5 * math.Pi}
+	omegas, values := ZeroTempImGc0(*env, k)
+	omegas3D, values3D := ZeroTemp3DImGc0(*env, Vector3{k.X, k.Y, 0.3})
+	for i, omega := range omegas {
+		if !FuzzierEqual(omega, omegas3D[i]) || math.Abs(values[i]-values3D[i]) > 1e-12 {
+			t.Fatalf("3D ImGc0 does not match 2D at omega = %f (got %e, expected %e)", omega, values3D[i], values[i])
+		}
+	}
+}
+
+// The 3D electron energy should match the 2D one without interlayer hopping
+// and add -2Tez*cos(kz) with it.
+func TestZeroTemp3DElectronEnergy(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := Vector2{0.3, -1.2}
+	energy := ZeroTempElectronEnergy(*env, k)
+	if e := ZeroTemp3DElectronEnergy(*env, Vector3{k.X, k.Y, 0.7}); e != energy {
+		t.Fatalf("3D electron energy with Tez = 0 (%f) differs from 2D (%f)", e, energy)
+	}
+	env.Tez = 0.05
+	for _, kz := range []float64{0.0, math.Pi / 2, math.Pi} {
+		expected := energy - 2*env.Tez*math.Cos(kz)
+		if e := ZeroTemp3DElectronEnergy(*env, Vector3{k.X, k.Y, kz}); math.Abs(e-expected) > 1e-12 {
+			t.Fatalf("3D electron energy at kz = %f is %f; expected %f", kz, e, expected)
+		}
+	}
+}
@@ -1,25 +1,48 @@
 package polecalc
 
 import (
+	"errors"
 	"fmt"
 	"math"
 )
 
 var imGc0Cache = NewListCache()
 
+// Calculates ImGc0 at all omega for a given k; see ZeroTempImGc0.
+type ImGc0Func func(env Environment, k Vector2) ([]float64, []float64)
+
+// Occupation factor (Bose or Fermi) as a function of energy.
+type OccupationFunc func(energy float64) float64
+
 // --- noninteracting Green's function for the physical electron ---
 
 // -- imaginary part of noninteracting Green's function --
 func deltaTermsGc0(env Environment, k Vector2, q Vector2) ([]float64, []float64) {
+	// bose function of omega_q = ZeroTempOmega(q) is 0 at T = 0
+	// since omega_q > 0 and mu < 0
+	bose := func(energy float64) float64 {
+		return 0.0
+	}
+	return thermalDeltaTermsGc0(env, k, q, bose, ZeroTempFermi)
+}
+
+// Delta function terms of ImGc0 with occupation factors n_q = bose(omega_q)
+// and f = fermi(E_h(q-k)).
+func thermalDeltaTermsGc0(env Environment, k Vector2, q Vector2, bose, fermi OccupationFunc) ([]float64, []float64) {
 	omega_q := ZeroTempOmega(env, q)
 	E_h := ZeroTempPairEnergy(env, q.Sub(k))
+	xi := Xi(env, q.Sub(k))
+	return energyDeltaTermsGc0(env, omega_q, E_h, xi, bose, fermi)
+}
+
+// Delta function terms of ImGc0 given the spinon energy omega_q and the holon
+// energies E_h(q-k) and xi(q-k).
+func energyDeltaTermsGc0(env Environment, omega_q, E_h, xi float64, bose, fermi OccupationFunc) ([]float64, []float64) {
 	lambda_p, lambda_m := plusMinus(1, env.Lambda()/omega_q)
-	// 0 in f_p & f_m is really bose function of omega_q = ZeroTempOmega(q)
-	// since omega_q > 0 and mu < 0, bose function result is 0
-	f_p, f_m := plusMinus(0, ZeroTempFermi(E_h))
+	// f_p = n_q + f; f_m + 1 = n_q + 1 - f
+	f_p, f_m := plusMinus(bose(omega_q), fermi(E_h))
 	if env.Superconducting {
 		c := -0.25 * math.Pi
-		xi := Xi(env, q.Sub(k))
 		xi_p, xi_m := plusMinus(1, xi/E_h)
 		omegas := []float64{omega_q - E_h, omega_q + E_h, -omega_q - E_h, -omega_q + E_h}
 
@@ -36,6 +59,30 @@ func deltaTermsGc0(env Environment, k Vector2, q Vector2) ([]float64, []float64)
 // values for all omega are calculated simultaneously, so return two slices of 
 // floats.  first is omega values, second is coefficients
 func ZeroTempImGc0(env Environment, k Vector2) ([]float64, []float64) {
+	deltaTerms := func(q Vector2) ([]float64, []float64) {
+		return deltaTermsGc0(env, k, q)
+	}
+	return binImGc0(env, k, deltaTerms)
+}
+
+// Bin the delta function terms of ImGc0 over an omega range wide enough to
+// hold all of them, using the method given by env.ImGc0Method.  With
+// HistogramMethod, terms are broadened as given by env.Broadening.
+func binImGc0(env Environment, k Vector2, deltaTerms DeltaTermsFunc) ([]float64, []float64) {
+	omegaMin, omegaMax := imGc0OmegaRange(env, k)
+	if env.ImGc0Method == TriangleMethod {
+		omegas := BinVarValues(omegaMin, omegaMax, env.ImGc0Bins)
+		return omegas, TriangleBin(env.GridLength, deltaTerms, omegaMin, omegaMax, env.ImGc0Bins)
+	}
+	binner := NewBroadenedDeltaBinner(deltaTerms, omegaMin, omegaMax, env.ImGc0Bins, env.BroadeningKernel())
+	omegas := binner.BinVarValues()
+	result := DeltaBin(env.GridLength, binner)
+	return omegas, result
+}
+
+// Range of omega containing all the delta function terms of ImGc0 at k.
+// For k on the GridLength mesh this range does not depend on k.
+func imGc0OmegaRange(env Environment, k Vector2) (float64, float64) {
 	var omegaMin, omegaMax float64
 	if env.Superconducting {
 		pairWorker := func(q Vector2) float64 {
@@ -52,17 +99,11 @@ func ZeroTempImGc0(env Environment, k Vector2) ([]float64, []float64) {
 		maxAbsOmega := env.Lambda() + xiMax
 		omegaMin, omegaMax = -maxAbsOmega-1.0, maxAbsOmega+1.0
 	}
-	deltaTerms := func(q Vector2) ([]float64, []float64) {
-		return deltaTermsGc0(env, k, q)
-	}
-	binner := NewDeltaBinner(deltaTerms, omegaMin, omegaMax, env.ImGc0Bins)
-	result := DeltaBin(env.GridLength, binner)
-	omegas := binner.BinVarValues()
-	return omegas, result
+	return omegaMin, omegaMax
 }
 
-func cachedImGc0(env Environment, k Vector2) (*CubicSpline, bool) {
-	kCacheInterface, ok := imGc0Cache.Get(env)
+func cachedImGc0(cache *ListCache, env Environment, k Vector2) (*CubicSpline, bool) {
+	kCacheInterface, ok := cache.Get(env)
 	if !ok {
 		return nil, false
 	}
@@ -74,31 +115,32 @@ func cachedImGc0(env Environment, k Vector2) (*CubicSpline, bool) {
 	return nil, ok
 }
 
-func addToCacheImGc0(env Environment, k Vector2, spl *CubicSpline) {
-	if !imGc0Cache.Contains(env) {
+func addToCacheImGc0(cache *ListCache, env Environment, k Vector2, spl *CubicSpline) {
+	if !cache.Contains(env) {
 		// env not encountered yet
 		kCache := *NewVectorCache()
 		kCache.Set(k, spl)
-		imGc0Cache.Set(env, kCache)
+		cache.Set(env, kCache)
 	} else {
-		kCacheInterface, _ := imGc0Cache.Get(env)
+		kCacheInterface, _ := cache.Get(env)
 		kCache := kCacheInterface.(VectorCache)
 		kCache.Set(k, spl)
 	}
 }
 
-func getFromCacheImGc0(env Environment, k Vector2) (*CubicSpline, error) {
+// Return the spline of imGc0(env, k), calculating it if it is not in cache.
+func getFromCacheImGc0(cache *ListCache, imGc0 ImGc0Func, env Environment, k Vector2) (*CubicSpline, error) {
 	var imPart *CubicSpline
-	if cache, ok := cachedImGc0(env, k); ok {
-		imPart = cache
+	if spl, ok := cachedImGc0(cache, env, k); ok {
+		imPart = spl
 	} else {
 		var err error
-		imPartOmegaVals, imPartFuncVals := ZeroTempImGc0(env, k)
+		imPartOmegaVals, imPartFuncVals := imGc0(env, k)
 		imPart, err = NewCubicSpline(imPartOmegaVals, imPartFuncVals)
 		if err != nil {
 			return nil, err
 		}
-		addToCacheImGc0(env, k, imPart)
+		addToCacheImGc0(cache, env, k, imPart)
 	}
 	return imPart, nil
 }
@@ -106,10 +148,14 @@ func getFromCacheImGc0(env Environment, k Vector2) (*CubicSpline, error) {
 // implementing this the lazy way for now by interpolating ImGc0(k)
 // could also calculate ImGc0(k,omega) directly
 func ZeroTempImGc0Point(env Environment, k Vector2, omega float64) (float64, error) {
-	imPart, err := getFromCacheImGc0(env, k)
+	imPart, err := getFromCacheImGc0(imGc0Cache, ZeroTempImGc0, env, k)
 	if err != nil {
 		return 0.0, err
 	}
+	return splineImGc0Point(imPart, omega)
+}
+
+func splineImGc0Point(imPart *CubicSpline, omega float64) (float64, error) {
 	omegaMin, omegaMax := imPart.Range()
 	if omegaMin <= omega && omega <= omegaMax {
 		return imPart.At(omega)
@@ -119,10 +165,15 @@ func ZeroTempImGc0Point(env Environment, k Vector2, omega float64) (float64, err
 
 // -- real part of noninteracting Green's function --
 func ZeroTempReGc0(env Environment, k Vector2, omega float64) (float64, error) {
-	imPart, err := getFromCacheImGc0(env, k)
+	imPart, err := getFromCacheImGc0(imGc0Cache, ZeroTempImGc0, env, k)
 	if err != nil {
 		return 0.0, err
 	}
+	return splineReGc0(env, imPart, omega)
+}
+
+// Kramers-Kronig transform of the spline of ImGc0 at omega.
+func splineReGc0(env Environment, imPart *CubicSpline, omega float64) (float64, error) {
 	omegaMin, omegaMax := imPart.Range()
 	// assume that Im(Gc0) is smooth near omegaPrime, so that spline
 	// interpolation is good enough
@@ -156,9 +207,8 @@ type ZeroTempGreenArgs struct {
 	Omega float64
 }
 
-func (eq ZeroTempGreenPoleEq) AbsError(args interface{}) float64 {
-	greenArgs := args.(ZeroTempGreenArgs)
-	env, omega := greenArgs.Env, greenArgs.Omega
+func (eq ZeroTempGreenPoleEq) AbsError(args ZeroTempGreenArgs) float64 {
+	env, omega := args.Env, args.Omega
 	ReGc0, err := ZeroTempReGc0(env, eq.K, omega)
 	if err != nil {
 		panic("error encountered searching for ReGc0: " + err.Error())
@@ -174,74 +224,149 @@ func (eq ZeroTempGreenPoleEq) AbsError(args interface{}) float64 {
 	return 1.0 - epsilon_k*ReGc0
 }
 
-func (eq ZeroTempGreenPoleEq) SetArguments(omega float64, args interface{}) interface{} {
-	env := args.(ZeroTempGreenArgs).Env
-	return ZeroTempGreenArgs{env, omega}
+func (eq ZeroTempGreenPoleEq) SetArguments(omega float64, args ZeroTempGreenArgs) ZeroTempGreenArgs {
+	return ZeroTempGreenArgs{args.Env, omega}
 }
 
-func (eq ZeroTempGreenPoleEq) Range(args interface{}) (float64, float64, error) {
-	env := args.(ZeroTempGreenArgs).Env
-	return -10.0 * env.T, 10.0 * env.T, nil
+func (eq ZeroTempGreenPoleEq) Range(args ZeroTempGreenArgs) (float64, float64, error) {
+	return -10.0 * args.Env.T, 10.0 * args.Env.T, nil
 }
 
-// return all pole omegas at a given k
-func ZeroTempGreenPolePoint(env Environment, k Vector2) ([]float64, error) {
+// return all poles at a given k
+func ZeroTempGreenPolePoint(env Environment, k Vector2) ([]GreenPole, error) {
 	// find brackets for all the poles
 	// lazy for now - only look at one solution
 	eq := ZeroTempGreenPoleEq{k}
 	initArgs := ZeroTempGreenArgs{env, 0.0}
-	solvedArgs, err := MultiSolve(eq, initArgs)
+	solvedArgs, err := MultiSolveWith[ZeroTempGreenArgs](eq, initArgs, BisectionFinder{}, 0.0, 0.0)
 	if err != nil {
 		return nil, err
 	}
-	solutions := []float64{}
+	solutions := []GreenPole{}
 	for _, args := range solvedArgs {
-		omega := args.(ZeroTempGreenArgs).Omega
-		solutions = append(solutions, omega)
+		omega := args.Omega
+		residue, err := ZeroTempPoleResidue(env, k, omega)
+		if err != nil {
+			return nil, err
+		}
+		solutions = append(solutions, GreenPole{k, omega, residue})
 	}
 	return solutions, nil
 }
 
+// Distance in omega used for the numerical derivative in ZeroTempPoleResidue.
+const PoleResidueStep = 1e-4
+
+// Quasiparticle residue Z = 1/|d(Re[1/G])/d(omega)| at a pole (k, omega)
+// found by ZeroTempGreenPoleEq.  Spurious sign changes of the pole equation
+// near the 1/x singularities of ReGc0 have very steep slopes and so get Z ~ 0.
+func ZeroTempPoleResidue(env Environment, k Vector2, omega float64) (float64, error) {
+	reGc0 := func(omega float64) (float64, error) {
+		return ZeroTempReGc0(env, k, omega)
+	}
+	return poleResidue(reGc0, ZeroTempElectronEnergy(env, k), omega)
+}
+
+// Residue at a zero omega of the pole equation f = 1 - epsilon_k*ReGc0.
+// There ReGc0 = 1/epsilon_k, so with Re[1/G] = 1/ReGc0 - epsilon_k,
+// d(Re[1/G])/d(omega) = d(f/ReGc0)/d(omega) = epsilon_k * df/d(omega).
+// The derivative of f is taken by central difference.
+func poleResidue(reGc0 func(float64) (float64, error), epsilon_k, omega float64) (float64, error) {
+	h := PoleResidueStep
+	right, err := reGc0(omega + h)
+	if err != nil {
+		return 0.0, err
+	}
+	left, err := reGc0(omega - h)
+	if err != nil {
+		return 0.0, err
+	}
+	df := -epsilon_k * (right - left) / (2 * h)
+	return math.Abs(1 / (epsilon_k * df)), nil
+}
+
 // real part of the full Green's function
 func FullReGc(env Environment, k Vector2, omega float64) (float64, error) {
-	ReGc0, err := ZeroTempReGc0(env, k, omega)
+	ReGc0, ImGc0, err := zeroTempGc0(env, k, omega)
 	if err != nil {
 		return 0.0, err
 	}
-	ImGc0, err := ZeroTempImGc0Point(env, k, omega)
+	return fullReGcFromGc0(ZeroTempElectronEnergy(env, k), ReGc0, ImGc0), nil
+}
+
+// imaginary part of the full Green's function
+func FullImGc(env Environment, k Vector2, omega float64) (float64, error) {
+	ReGc0, ImGc0, err := zeroTempGc0(env, k, omega)
 	if err != nil {
 		return 0.0, err
 	}
-	mag := ReGc0*ReGc0 + ImGc0*ImGc0
-	epsilon_k := ZeroTempElectronEnergy(env, k)
+	return fullImGcFromGc0(ZeroTempElectronEnergy(env, k), ReGc0, ImGc0), nil
+}
+
+// Spectral function A(k, omega) = -Im[G(k, omega)] / pi
+func SpectralFunction(env Environment, k Vector2, omega float64) (float64, error) {
+	ImGc, err := FullImGc(env, k, omega)
+	if err != nil {
+		return 0.0, err
+	}
+	return -ImGc / math.Pi, nil
+}
+
+// real and imaginary parts of Gc0 at (k, omega)
+func zeroTempGc0(env Environment, k Vector2, omega float64) (float64, float64, error) {
+	ReGc0, err := ZeroTempReGc0(env, k, omega)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	ImGc0, err := ZeroTempImGc0Point(env, k, omega)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	return ReGc0, ImGc0, nil
+}
+
+// G = 1/(1/Gc0 - epsilon_k); with Gc0 = a + ib and |Gc0|^2 = m,
+// G = m(a - m*epsilon_k + ib) / ((a - m*epsilon_k)^2 + b^2)
+func fullReGcFromGc0(epsilon_k, ReGc0, ImGc0 float64) float64 {
+	mag, denom := fullGcFactors(epsilon_k, ReGc0, ImGc0)
 	numer := mag * (ReGc0 - mag*epsilon_k)
+	return numer / denom
+}
+
+func fullImGcFromGc0(epsilon_k, ReGc0, ImGc0 float64) float64 {
+	mag, denom := fullGcFactors(epsilon_k, ReGc0, ImGc0)
+	return mag * ImGc0 / denom
+}
+
+func fullGcFactors(epsilon_k, ReGc0, ImGc0 float64) (float64, float64) {
+	mag := ReGc0*ReGc0 + ImGc0*ImGc0
 	denom := math.Pow(ReGc0-mag*epsilon_k, 2.0) + ImGc0*ImGc0
-	return numer / denom, nil
+	return mag, denom
 }
 
 // --- plotting helper functions ---
 
 type GreenPole struct {
-	K     Vector2
-	Omega float64
+	K       Vector2
+	Omega   float64
+	Residue float64 // quasiparticle weight Z
 }
 
 func (gp GreenPole) String() string {
-	return fmt.Sprintf("k: %v; omega: %f", gp.K, gp.Omega)
+	return fmt.Sprintf("k: %v; omega: %f; Z: %f", gp.K, gp.Omega, gp.Residue)
 }
 
 func capturePoles(env Environment, k Vector2, poles []GreenPole) ([]GreenPole, error) {
 	kPoles, err := ZeroTempGreenPolePoint(env, k)
 	if err != nil {
-		if err.Error() == ErrorNoBracket {
+		if errors.Is(err, ErrorNoBracket) {
 			println("bracket error at k = ", k.String())
 			return poles, nil
 		}
 		return poles, err
 	} else {
 		for _, p := range kPoles {
-			println("got pole k=", k.String(), p)
-			poles = append(poles, GreenPole{k, p})
+			poles = append(poles, p)
 		}
 	}
 	return poles, err
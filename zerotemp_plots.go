@@ -94,7 +94,18 @@ func ZeroTempPlotPolePlane(env Environment, outputPath string, sideLength uint32
 	if err != nil {
 		return err
 	}
-	graphPoleData(polePlane, outputPath, &Vector2{32.0, 32.0})
+	graphPoleData(polePlane, outputPath, &Vector2{32.0, 32.0}, false)
+	return nil
+}
+
+// Plot the poles throughout the k plane with marker size and colour given by
+// the quasiparticle weight of each pole.
+func ZeroTempPlotPolePlaneWeights(env Environment, outputPath string, sideLength uint32) error {
+	polePlane, err := ZeroTempGreenPolePlane(env, sideLength, true)
+	if err != nil {
+		return err
+	}
+	graphPoleData(polePlane, outputPath, &Vector2{32.0, 32.0}, true)
 	return nil
 }
 
@@ -105,15 +116,32 @@ func ZeroTempPlotPoleCurve(env Environment, poleCurve func(float64) Vector2, num
 	if err != nil {
 		return err
 	}
-	graphPoleData(polePoints, outputPath, nil)
+	graphPoleData(polePoints, outputPath, nil, false)
+	return nil
+}
+
+// Plot the line of poles specified by poleCurve, with marker size and colour
+// given by the quasiparticle weight of each pole.
+func ZeroTempPlotPoleCurveWeights(env Environment, poleCurve func(float64) Vector2, numPoints uint, outputPath string) error {
+	polePoints, err := ZeroTempGreenPoleCurve(env, poleCurve, numPoints)
+	if err != nil {
+		return err
+	}
+	graphPoleData(polePoints, outputPath, nil, true)
 	return nil
 }
 
-func graphPoleData(poles []GreenPole, outputPath string, dims *Vector2) {
+// If weighted, the residue of each pole is included as a third column and
+// used to set marker size and colour.
+func graphPoleData(poles []GreenPole, outputPath string, dims *Vector2, weighted bool) {
 	poleData := [][]float64{}
 	for _, gp := range poles {
 		k := gp.K
-		poleData = append(poleData, []float64{k.X, k.Y})
+		if weighted {
+			poleData = append(poleData, []float64{k.X, k.Y, gp.Residue})
+		} else {
+			poleData = append(poleData, []float64{k.X, k.Y})
+		}
 	}
 	poleGraph := NewGraph()
 	params := make(map[string]interface{})
@@ -122,6 +150,50 @@ func graphPoleData(poles []GreenPole, outputPath string, dims *Vector2) {
 	}
 	params["graph_filepath"] = outputPath
 	poleGraph.SetGraphParameters(params)
-	poleGraph.AddSeries(map[string]string{"label": "poles", "style": "k."}, poleData)
+	if weighted {
+		poleGraph.AddSeries(map[string]string{"label": "poles", "kind": "scatter"}, poleData)
+	} else {
+		poleGraph.AddSeries(map[string]string{"label": "poles", "style": "k."}, poleData)
+	}
 	MakePlot(poleGraph, outputPath)
 }
+
+// Plot the energy distribution curves A(k, omega) in grid, one series per k,
+// each offset vertically from the last to give an ARPES-style stack.
+func PlotSpectralGrid(grid *SpectralGrid, outputPath string) error {
+	offset := 0.0
+	for _, kValues := range grid.Values {
+		for _, A := range kValues {
+			offset = math.Max(offset, A)
+		}
+	}
+	graph := NewGraph()
+	graph.SetGraphParameters(map[string]interface{}{"graph_filepath": outputPath, "xlabel": "$\\omega$", "ylabel": "$A(k, \\omega)$"})
+	for i, k := range grid.Ks {
+		data := make([][]float64, len(grid.Omegas))
+		for j, omega := range grid.Omegas {
+			data[j] = []float64{omega, grid.Values[i][j] + float64(i)*offset}
+		}
+		graph.AddSeries(map[string]string{"label": k.String(), "style": "k-"}, data)
+	}
+	return MakePlot(graph, outputPath)
+}
+
+// Plot the Fermi surface contours as polylines.  Contours are split where
+// they cross the zone boundary so that no line is drawn across the zone.
+func PlotFermiSurface(fs *FermiSurfaceData, outputPath string) error {
+	graph := NewGraph()
+	graph.SetGraphParameters(map[string]interface{}{"graph_filepath": outputPath, "dimensions": []float64{8.0, 8.0}, "xlabel": "$k_x$", "ylabel": "$k_y$"})
+	for _, contour := range fs.Contours {
+		data := [][]float64{}
+		for i, k := range contour {
+			if i > 0 && !minimumImage(k.Sub(contour[i-1])).Equals(k.Sub(contour[i-1])) {
+				graph.AddSeries(map[string]string{"style": "k-"}, data)
+				data = [][]float64{}
+			}
+			data = append(data, []float64{k.X, k.Y})
+		}
+		graph.AddSeries(map[string]string{"style": "k-"}, data)
+	}
+	return MakePlot(graph, outputPath)
+}
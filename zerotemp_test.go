@@ -33,6 +33,46 @@ func TestKnownZeroTempSystem(t *testing.T) {
 	}
 }
 
+// Does each pole carry a finite, non-negative residue?
+func TestPoleResidue(t *testing.T) {
+	env, err := EnvironmentFromFile("zerotemp_test_gc0_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.GridLength = 16
+	env.ImGc0Bins = 128
+	env.ReGc0Points = 64
+	k := Vector2{0.0, 0.0}
+	poles, err := ZeroTempGreenPolePoint(*env, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(poles) == 0 {
+		t.Fatal("no poles found")
+	}
+	for _, p := range poles {
+		if math.IsNaN(p.Residue) || math.IsInf(p.Residue, 0) || p.Residue < 0 {
+			t.Fatalf("invalid residue for pole %v", p)
+		}
+	}
+}
+
+// For a single pole Gc0 = a/(omega - w0), G = a/(omega - w0 - epsilon*a) has
+// residue a at omega = w0 + epsilon*a.
+func TestPoleResidueSinglePole(t *testing.T) {
+	a, w0, epsilon := 0.3, -0.2, 1.5
+	reGc0 := func(omega float64) (float64, error) {
+		return a / (omega - w0), nil
+	}
+	Z, err := poleResidue(reGc0, epsilon, w0+epsilon*a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(Z-a) > 1e-6 {
+		t.Fatalf("incorrect residue %f (expected %f)", Z, a)
+	}
+}
+
 func TestGc0(t *testing.T) {
 	cacheFileName := "zerotemp_test_gc0_cache.json"
 	flag.Parse()